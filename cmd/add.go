@@ -0,0 +1,202 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the add command, which interactively stages files for committing.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/commit"
+	"commitsense/pkg/hunk"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	csprompt "commitsense/pkg/prompt"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	hunkOptionStage = "Stage this hunk"
+	hunkOptionSkip  = "Skip this hunk"
+	hunkOptionSplit = "Split into smaller hunks"
+	hunkOptionQuit  = "Quit, staging the hunks selected so far"
+)
+
+var errNothingToStage = errors.New("there are no changes to stage")
+
+var addPatch bool
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Interactively select the files to stage for committing",
+	Long: `Interactively select the files to stage for committing.
+
+With --patch the unstaged changes are shown hunk by hunk, and each hunk can be
+staged, skipped or split into smaller hunks.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		stage := stageFilesInteractively
+		if addPatch {
+			stage = stageHunksInteractively
+		}
+
+		if err := stage(); err != nil {
+			colorprinter.ColorPrint("error", "Error staging files: %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// stageFilesInteractively lists the changed files of the working tree and lets the user toggle which
+// of them are staged. Files that are already staged are selected initially.
+func stageFilesInteractively() error {
+	files, err := commit.GetChangedFiles()
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return errNothingToStage
+	}
+
+	items := make([]*csprompt.Item, 0, len(files))
+	for _, file := range files {
+		file := file
+		items = append(items, &csprompt.Item{
+			ID:          file.Path,
+			IsSelected:  file.IsStaged,
+			Description: fmt.Sprintf("(%s)", file.Status),
+			Details: func() string {
+				return commit.GetFileDiffPreview(file)
+			},
+		})
+	}
+
+	if _, err := csprompt.MultiSelect("Select the files to stage", items); err != nil {
+		return err
+	}
+
+	var toStage, toUnstage []string
+	for i, file := range files {
+		switch {
+		case items[i].IsSelected && !file.IsStaged:
+			toStage = append(toStage, file.Path)
+		case !items[i].IsSelected && file.IsStaged:
+			toUnstage = append(toUnstage, file.Path)
+		}
+	}
+
+	if err := commit.StageFiles(toStage); err != nil {
+		return err
+	}
+
+	return commit.UnstageFiles(toUnstage)
+}
+
+// stageHunksInteractively shows the unstaged changes hunk by hunk and stages the hunks the user selects.
+func stageHunksInteractively() error {
+	diff, err := commit.GetUnstagedDiff()
+	if err != nil {
+		return err
+	}
+
+	files, err := hunk.Parse(diff)
+	if err != nil {
+		return fmt.Errorf("could not parse the unstaged changes: %w", err)
+	}
+
+	if len(files) == 0 {
+		return errNothingToStage
+	}
+
+	var patch strings.Builder
+	for _, file := range files {
+		if file.IsBinary || len(file.Hunks) == 0 {
+			colorprinter.ColorPrint("info", "Skipping %s, it has no text changes to stage", file.NewPath)
+			continue
+		}
+
+		selected, quit, err := selectHunks(file)
+		if err != nil {
+			return err
+		}
+
+		patch.WriteString(file.Patch(selected))
+
+		if quit {
+			break
+		}
+	}
+
+	if patch.Len() == 0 {
+		return nil
+	}
+
+	return commit.ApplyPatchToIndex(patch.String())
+}
+
+// selectHunks prompts the user whether to stage each hunk of the file, and returns the selected hunks
+// in the order they appear in the file, and whether the user chose to quit.
+func selectHunks(file hunk.File) ([]hunk.Hunk, bool, error) {
+	queue := append([]hunk.Hunk{}, file.Hunks...)
+
+	var selected []hunk.Hunk
+	for len(queue) > 0 {
+		h := queue[0]
+		queue = queue[1:]
+
+		printHunk(file, h)
+
+		options := []string{hunkOptionStage, hunkOptionSkip}
+		split := h.Split()
+		if len(split) > 1 {
+			options = append(options, hunkOptionSplit)
+		}
+		options = append(options, hunkOptionQuit)
+
+		index, err := csprompt.Select("Stage this hunk of "+file.NewPath, options)
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch options[index] {
+		case hunkOptionStage:
+			selected = append(selected, h)
+		case hunkOptionSplit:
+			queue = append(split, queue...)
+		case hunkOptionQuit:
+			return selected, true, nil
+		}
+	}
+
+	return selected, false, nil
+}
+
+func printHunk(file hunk.File, h hunk.Hunk) {
+	fmt.Println()
+	colorprinter.ColorPrint("bold", "diff --git a/%s b/%s", file.OldPath, file.NewPath)
+	colorprinter.ColorPrint("info", "%s", h.Header())
+
+	for _, line := range h.Lines {
+		switch line[0] {
+		case '+':
+			colorprinter.ColorPrint("success", "%s", line)
+		case '-':
+			colorprinter.ColorPrint("error", "%s", line)
+		default:
+			colorprinter.ColorPrint("stdout", "%s", line)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+
+	addCmd.Flags().BoolVarP(&addPatch, "patch", "p", false, "Select the hunks to stage instead of whole files")
+}
@@ -0,0 +1,132 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the changelog command, which generates a changelog from the Conventional Commits history.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/changelog"
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultChangelogFile = "CHANGELOG.md"
+
+var (
+	changelogFrom    string
+	changelogTo      string
+	changelogTitle   string
+	changelogOutput  string
+	changelogPrepend bool
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate a changelog from the commit history",
+	Long: `Generate a Markdown changelog in the Keep a Changelog style from the commits
+between two refs or tags. By default the commits since the latest tag are used.
+
+  commitsense changelog
+  commitsense changelog --from v1.0.0 --to v1.1.0 --title 1.1.0
+  commitsense changelog --title 1.2.0 --prepend`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		cfg, err := config.Read()
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error reading the configuration: %v", err)
+			os.Exit(1)
+		}
+
+		revisionRange, err := changelogRevisionRange()
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+
+		commits, err := commit.GetCommitLog(revisionRange)
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+
+		release := changelog.Release{Title: changelogTitle, Commits: commits}
+		if changelogTitle != "Unreleased" {
+			release.Date = time.Now()
+		}
+
+		section := changelog.Generate(release, cfg)
+
+		if err := writeChangelog(section); err != nil {
+			colorprinter.ColorPrint("error", "Error writing the changelog: %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// changelogRevisionRange returns the revision range of the release, starting from the latest tag if not set.
+func changelogRevisionRange() (string, error) {
+	from := changelogFrom
+	if from == "" {
+		latestTag, err := commit.GetLatestTag()
+		if err != nil {
+			return "", err
+		}
+		from = latestTag
+	}
+
+	if from == "" {
+		return changelogTo, nil
+	}
+
+	return from + ".." + changelogTo, nil
+}
+
+// writeChangelog writes the changelog section to stdout or to the output file.
+func writeChangelog(section string) error {
+	output := changelogOutput
+	if output == "" && changelogPrepend {
+		output = defaultChangelogFile
+	}
+
+	if output == "" {
+		fmt.Print(section)
+		return nil
+	}
+
+	content := changelog.Header + "\n" + section
+	if changelogPrepend {
+		existing, err := os.ReadFile(output)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		content = changelog.Prepend(string(existing), section)
+	}
+
+	if err := os.WriteFile(output, []byte(content), 0o644); err != nil { //nolint:gosec // changelogs are public
+		return err
+	}
+
+	colorprinter.ColorPrint("success", "Wrote the changelog to %v", output)
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+
+	changelogCmd.Flags().StringVar(&changelogFrom, "from", "", "Start of the history, exclusive (default: latest tag)")
+	changelogCmd.Flags().StringVar(&changelogTo, "to", "HEAD", "End of the history, inclusive")
+	changelogCmd.Flags().StringVarP(&changelogTitle, "title", "t", "Unreleased", "Title of the release, e.g. the version")
+	changelogCmd.Flags().StringVarP(&changelogOutput, "output", "o", "", "Write the changelog to a file instead of stdout")
+	changelogCmd.Flags().BoolVarP(&changelogPrepend, "prepend", "p", false, "Prepend to the output file, "+defaultChangelogFile+" by default")
+}
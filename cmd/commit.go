@@ -15,6 +15,10 @@ package cmd
 import (
 	"commitsense/internal/validators"
 	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"commitsense/pkg/editor"
+	"errors"
+	"fmt"
 	"os"
 
 	colorprinter "commitsense/internal/printer"
@@ -26,81 +30,126 @@ import (
 var (
 	isCoAuthored     bool
 	isBreakingChange bool
+	useEditor        bool
+	onlyPaths        []string
 )
 
+// bodyTemplate is the template of the commit body opened in the editor, filled with the header of the
+// commit and the wrap width.
+const bodyTemplate = `# Enter the body of the commit message for:
+#
+#   %s
+#
+# Lines starting with '#' are ignored, and lines longer than %d characters
+# are wrapped. Separate paragraphs with blank lines, and leave the body
+# empty to commit without one.`
+
 // CommitCmd represents the commit command.
 var commitCmd = &cobra.Command{
 	Use:   "commit",
 	Short: "Create a commit with a standardized message",
-	Run: func(_ *cobra.Command, _ []string) {
-		stagedFiles, err := commit.GetStagedFiles()
+	Long: `Create a commit with a standardized message.
+
+The fields of the commit message are prompted for, unless they are given with
+the flags or in a serialized commit read with --from-json:
+
+  commitsense commit --type feat --scope api --description "Add pagination"
+  echo '{"type": "fix", "description": "Fix a crash"}' | commitsense commit --from-json - --no-input
+
+Only the missing fields are prompted for. With --no-input nothing is prompted
+for, and the command fails when the commit type or the description is missing.
+
+Exactly the changes staged in the index are committed. With --only the current
+content of the given files is committed instead, and the other staged changes
+are left in the index:
+
+  commitsense commit --only src/api.go --only src/api_test.go
+
+With --dry-run the message and the git command are printed instead of creating
+the commit, and with --output json the commit is printed as JSON. Nothing is
+prompted for with --output json, as with --no-input.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		cfg, err := config.Read()
 		if err != nil {
-			colorprinter.ColorPrint("error", "Error: %v", err)
+			colorprinter.ColorPrint("error", "Error reading the configuration: %v", err)
 			os.Exit(1)
 		}
 
-		commitType, err := csprompt.CommitType("Select a commit type")
+		printOnly, err := isDryRun()
 		if err != nil {
-			colorprinter.ColorPrint("error", "Error prompting for the commit type: %v", err)
+			colorprinter.ColorPrint("error", "Error: %v", err)
 			os.Exit(1)
 		}
 
-		commitScope, err := csprompt.String("Enter a commit scope (optional)", nil)
+		// The prompts and the review would be printed in the middle of the JSON, so every field must be given.
+		if outputFormat == outputJSON {
+			noInput = true
+		}
+
+		stagedFiles, err := commit.GetStagedFiles()
+		var unmerged *commit.UnmergedError
+		if err != nil && !errors.As(err, &unmerged) && (printOnly || len(onlyPaths) > 0) {
+			// The message can be printed without staged files, and a dry run must not stage any.
+			// With --only the files are committed from the working tree.
+			stagedFiles, err = nil, nil
+		}
+		// Staging more files does not resolve the conflicts.
+		if err != nil && (noInput || unmerged != nil) {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			// Nothing is staged yet, let the user pick the files to commit.
+			if err := stageFilesInteractively(); err != nil {
+				colorprinter.ColorPrint("error", "Error staging files: %v", err)
+				os.Exit(1)
+			}
+
+			stagedFiles, err = commit.GetStagedFiles()
+			if err != nil {
+				colorprinter.ColorPrint("error", "Error: %v", err)
+				os.Exit(1)
+			}
+		}
+
+		c, given, err := commitInput(cmd.Flags(), stagedFiles)
 		if err != nil {
-			colorprinter.ColorPrint("error", "Error prompting for the commit scope: %v", err)
+			colorprinter.ColorPrint("error", "Error: %v", err)
 			os.Exit(1)
 		}
 
-		commitDescription, err := csprompt.String(
-			"Enter a brief commit description",
-			validators.ValidateStringNotEmpty,
-		)
+		prompted, err := promptMissingFields(cfg, c, given)
 		if err != nil {
-			colorprinter.ColorPrint("error", "Error prompting for the commit description: %v", err)
+			colorprinter.ColorPrint("error", "Error: %v", err)
 			os.Exit(1)
 		}
 
-		commitBody, err := csprompt.MultilineString(
-			"Enter a detailed commit body (press Enter twice to finish)",
-		)
+		// The message is reviewed when it was composed interactively, otherwise it is only linted.
+		// With --output json the violations are part of the output instead.
+		if prompted {
+			c, err = reviewCommit(cfg, c)
+		} else if outputFormat != outputJSON {
+			err = checkCommit(cfg, c)
+		}
+		if errors.Is(err, errCommitAborted) {
+			colorprinter.ColorPrint("info", "The commit was aborted")
+			os.Exit(1)
+		}
 		if err != nil {
-			colorprinter.ColorPrint("error", "Error prompting for the commit body: %v", err)
+			colorprinter.ColorPrint("error", "Error: %v", err)
 			os.Exit(1)
 		}
 
-		var coAuthors []string
-		if isCoAuthored {
-			coAuthors, err = csprompt.CoAuthors(
-				"Enter Co-Author information ",
-			)
+		if printOnly {
+			failed, err := printDryRun(cfg, c)
 			if err != nil {
-				colorprinter.ColorPrint("error", "Error prompting for the co-authors: %v", err)
+				colorprinter.ColorPrint("error", "Error: %v", err)
 				os.Exit(1)
 			}
-		}
-
-		var breakingChangeDescription string
-		if isBreakingChange {
-			breakingChangeDescription, err = csprompt.String(
-				"Enter a description of the breaking change",
-				nil,
-			)
-			if err != nil {
-				colorprinter.ColorPrint("error", "Error prompting for the breaking change description: %v", err)
+			if failed {
 				os.Exit(1)
 			}
-		}
-
-		c := commit.Commit{
-			CommitType:                commitType,
-			CommitScope:               commitScope,
-			CommitDescription:         commitDescription,
-			CommitBody:                commitBody,
-			IsCoAuthored:              isCoAuthored,
-			CoAuthors:                 coAuthors,
-			IsBreakingChange:          isBreakingChange,
-			BreakingChangeDescription: breakingChangeDescription,
-			StagedFiles:               stagedFiles,
+			return
 		}
 
 		if err := c.CreateGitCommit(); err != nil {
@@ -110,9 +159,135 @@ var commitCmd = &cobra.Command{
 	},
 }
 
+// Fields of the commit message, which can also be prompted for again when reviewing the message.
+const (
+	fieldType        = "Commit type"
+	fieldScope       = "Scope"
+	fieldDescription = "Description"
+	fieldBody        = "Body"
+	fieldCoAuthors   = "Co-authors"
+	fieldBreaking    = "Breaking change description"
+)
+
+// promptCommit interactively prompts the user for the contents of a commit message.
+// The scope is suggested from the paths of the staged files.
+func promptCommit(cfg *config.Config, stagedFiles []commit.StagedFile) (*commit.Commit, error) {
+	c := &commit.Commit{
+		IsCoAuthored:     isCoAuthored,
+		IsBreakingChange: isBreakingChange,
+		StagedFiles:      stagedFiles,
+	}
+
+	if _, err := promptMissingFields(cfg, c, nil); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// commitFields returns the fields prompted for the commit in order.
+func commitFields(c *commit.Commit) []string {
+	fields := []string{fieldType, fieldScope, fieldDescription, fieldBody}
+
+	if c.IsCoAuthored {
+		fields = append(fields, fieldCoAuthors)
+	}
+	if c.IsBreakingChange {
+		fields = append(fields, fieldBreaking)
+	}
+
+	return fields
+}
+
+// promptField prompts the user for a single field of the commit.
+func promptField(cfg *config.Config, c *commit.Commit, field string) error {
+	var err error
+
+	switch field {
+	case fieldType:
+		c.CommitType, err = csprompt.CommitType("Select a commit type")
+		if err != nil {
+			return fmt.Errorf("prompting for the commit type: %w", err)
+		}
+	case fieldScope:
+		c.CommitScope, err = csprompt.Scope("Enter a commit scope", c.CommitType, cfg.SuggestScope(c.Paths()))
+		if err != nil {
+			return fmt.Errorf("prompting for the commit scope: %w", err)
+		}
+	case fieldDescription:
+		c.CommitDescription, err = csprompt.LiveString(
+			"Enter a brief commit description",
+			descriptionFeedback(cfg, c),
+			validators.ValidateStringNotEmpty,
+			validateDescription(cfg, c),
+		)
+		if err != nil {
+			return fmt.Errorf("prompting for the commit description: %w", err)
+		}
+	case fieldBody:
+		if useEditor || cfg.BodyEditor {
+			c.CommitBody, err = editBody(cfg, c)
+			if err != nil {
+				return fmt.Errorf("editing the commit body: %w", err)
+			}
+			break
+		}
+
+		c.CommitBody, err = csprompt.MultilineString(
+			"Enter a detailed commit body (press Enter twice to finish)",
+			bodyLineFeedback(cfg),
+			validateBodyLine(cfg),
+		)
+		if err != nil {
+			return fmt.Errorf("prompting for the commit body: %w", err)
+		}
+	case fieldCoAuthors:
+		c.CoAuthors, err = csprompt.CoAuthors(
+			"Enter Co-Author information ",
+		)
+		if err != nil {
+			return fmt.Errorf("prompting for the co-authors: %w", err)
+		}
+	case fieldBreaking:
+		c.BreakingChangeDescription, err = csprompt.String(
+			"Enter a description of the breaking change",
+		)
+		if err != nil {
+			return fmt.Errorf("prompting for the breaking change description: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// editBody opens the body of the commit in the editor of the user, and returns the edited body without
+// the comment lines and wrapped to the wrap width.
+func editBody(cfg *config.Config, c *commit.Commit) (string, error) {
+	width, _ := bodyWidth(cfg)
+
+	body, err := editor.Edit(c.CommitBody + "\n\n" + fmt.Sprintf(bodyTemplate, c.Header(), width) + "\n")
+	if err != nil {
+		return "", err
+	}
+
+	return commit.WrapBody(commit.StripComments(body), width), nil
+}
+
 func init() {
 	rootCmd.AddCommand(commitCmd)
 
 	commitCmd.Flags().BoolVarP(&isCoAuthored, "is-coauthored", "a", false, "Commit is co-authored")
 	commitCmd.Flags().BoolVarP(&isBreakingChange, "is-breaking", "b", false, "Commit is introducing a breaking change")
+	commitCmd.Flags().BoolVarP(&useEditor, "editor", "e", false, "Compose the commit body in the editor")
+	commitCmd.Flags().StringVar(&flagType, "type", "", "Commit type")
+	commitCmd.Flags().StringVarP(&flagScope, "scope", "s", "", "Commit scope")
+	commitCmd.Flags().StringVarP(&flagDescription, "description", "d", "", "Commit description")
+	commitCmd.Flags().StringArrayVarP(&flagBody, "body", "m", nil, "Paragraph of the commit body, can be repeated")
+	commitCmd.Flags().StringVarP(&flagBreaking, "breaking", "B", "", "Description of the breaking change, implies --is-breaking")
+	commitCmd.Flags().StringArrayVarP(&flagCoAuthors, "co-author", "c", nil, `Co-author of the commit as "Name <email>", can be repeated`)
+	commitCmd.Flags().StringArrayVarP(&flagTrailers, "trailer", "t", nil, `Trailer of the commit as "Token: value", can be repeated`)
+	commitCmd.Flags().StringVar(&fromJSON, "from-json", "", "Read the commit from a JSON file, use - for stdin")
+	commitCmd.Flags().BoolVar(&noInput, "no-input", false, "Fail instead of prompting for missing fields")
+	commitCmd.Flags().StringArrayVar(&onlyPaths, "only", nil, "Commit only the current content of the file instead of the index, can be repeated")
+	addDryRunFlags(commitCmd.Flags())
 }
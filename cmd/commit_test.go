@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"commitsense/pkg/git"
+	"reflect"
+	"testing"
+)
+
+func TestCommitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		message string
+		files   []git.FileStatus
+	}{
+		{
+			name:    "staged changes",
+			args:    []string{"commit", "--type", "feat", "--scope", "api", "--description", "Add pagination", "--no-input"},
+			message: "feat(api): Add pagination",
+			files:   []git.FileStatus{{Path: "b.go", Index: ' ', Worktree: 'M'}},
+		},
+		{
+			name:    "only some files",
+			args:    []string{"commit", "--type", "fix", "--description", "Handle empty pages", "--only", "b.go", "--no-input"},
+			message: "fix: Handle empty pages",
+			files:   []git.FileStatus{{Path: "a.go", Index: 'M', Worktree: ' '}},
+		},
+		{
+			name:    "body and trailers",
+			args:    []string{"commit", "--type", "feat", "--description", "Add pages", "-m", "The body.", "-t", "Reviewed-by: John Doe <john@example.com>", "--no-input"},
+			message: "feat: Add pages\n\nThe body.\n\nReviewed-by: John Doe <john@example.com>",
+			files:   []git.FileStatus{{Path: "b.go", Index: ' ', Worktree: 'M'}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hookMessages []string
+			repo := &git.MemoryRepository{
+				Dir: t.TempDir(),
+				Files: []git.FileStatus{
+					{Path: "a.go", Index: 'M', Worktree: ' '},
+					{Path: "b.go", Index: ' ', Worktree: 'M'},
+				},
+				Settings: map[string]string{"user.name": "Jane Doe", "user.email": "jane@example.com"},
+				Hooks: map[string]func(string) error{
+					"commit-msg": func(message string) error {
+						hookMessages = append(hookMessages, message)
+						return nil
+					},
+				},
+			}
+
+			executeCommand(t, repo, tt.args...)
+
+			if len(repo.History) != 1 || repo.History[0].Message != tt.message {
+				t.Fatalf("got history %+v, want a commit with the message %q", repo.History, tt.message)
+			}
+			if repo.History[0].Author != "Jane Doe <jane@example.com>" {
+				t.Errorf("got author %q, want %q", repo.History[0].Author, "Jane Doe <jane@example.com>")
+			}
+			if len(hookMessages) != 1 {
+				t.Errorf("the commit-msg hook ran %d times, want once", len(hookMessages))
+			}
+			if !reflect.DeepEqual(repo.Files, tt.files) {
+				t.Errorf("got files %+v after the commit, want %+v", repo.Files, tt.files)
+			}
+		})
+	}
+}
+
+func TestCommitCommandDryRun(t *testing.T) {
+	repo := &git.MemoryRepository{
+		Dir:   t.TempDir(),
+		Files: []git.FileStatus{{Path: "a.go", Index: 'M', Worktree: ' '}},
+	}
+
+	output := executeCommand(t, repo, "commit", "--type", "feat", "--description", "Add pagination", "--no-input", "--dry-run")
+
+	if want := "feat: Add pagination\n\ngit commit -m 'feat: Add pagination'\n"; output != want {
+		t.Errorf("got output %q, want %q", output, want)
+	}
+	if len(repo.History) != 0 || !repo.Files[0].IsStaged() {
+		t.Errorf("the dry run changed the repository: %+v, %+v", repo.History, repo.Files)
+	}
+}
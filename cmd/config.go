@@ -0,0 +1,200 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the config commands, which manage the CommitSense configuration file.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/config"
+	"commitsense/pkg/lint"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateGlobal bool
+	showOrigin    bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the CommitSense configuration",
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the configuration file to the current format version",
+	Long: `Upgrade the configuration file to the current format version.
+
+Configuration files of older versions are upgraded in memory on every run,
+this command rewrites the file in the current format. Keys unknown to
+CommitSense are kept as they are.
+
+The configuration file of the repository is migrated, or the global
+configuration file with --global.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		path := config.RepositoryFile()
+		if migrateGlobal {
+			path = config.GlobalFile()
+		}
+
+		version, err := config.MigrateFile(path)
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error migrating the configuration: %v", err)
+			os.Exit(1)
+		}
+
+		if version == config.CurrentVersion {
+			colorprinter.ColorPrint("info", "The configuration is already at version %d", version)
+			return
+		}
+
+		colorprinter.ColorPrint("success", "Migrated %s from version %d to version %d", path, version, config.CurrentVersion)
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the resolved configuration",
+	Long: `Print the resolved configuration.
+
+The configuration is resolved from the following layers, each overriding the
+keys set by the previous ones:
+
+  1. the built-in defaults
+  2. the global configuration file, $XDG_CONFIG_HOME/commitsense/config.json
+  3. the type-enum and scope-enum rules of a .commitlintrc file
+  4. the commitsense.config.json, .commitsense.yaml, .commitsense.toml or
+     package.json file in the root of the repository
+  5. the COMMITSENSE_<KEY> environment variables, e.g. COMMITSENSE_SKIP_CI_TYPES
+  6. the --config and --set flags
+
+With --origin the layer each value came from is printed as well.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		raw, origins, err := config.Load()
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error reading the configuration: %v", err)
+			os.Exit(1)
+		}
+
+		if !showOrigin {
+			data, err := json.MarshalIndent(raw, "", "  ")
+			if err != nil {
+				colorprinter.ColorPrint("error", "Error printing the configuration: %v", err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(data))
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, key := range config.SortedKeys(raw) {
+			value, err := json.Marshal(raw[key])
+			if err != nil {
+				colorprinter.ColorPrint("error", "Error printing the configuration: %v", err)
+				os.Exit(1)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\n", key, value, origins[key])
+		}
+		w.Flush()
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the configuration for problems",
+	Long: `Check the configuration for problems.
+
+The resolved configuration, or the given configuration file, is checked against
+the JSON Schema of the configuration file and for inconsistencies such as
+duplicate commit types, skip ci types that are not configured commit types and
+unknown keys. The lint rules are checked for unknown rule names and invalid
+options, such as malformed regular expressions. Each problem is reported with the JSON pointer of the value.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		problems, origins, err := getConfigProblems(args)
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error reading the configuration: %v", err)
+			os.Exit(1)
+		}
+
+		for _, problem := range problems {
+			if origin, ok := origins[problem.Key()]; ok {
+				colorprinter.ColorPrint("error", "✖ %v (%v)", problem, origin)
+			} else {
+				colorprinter.ColorPrint("error", "✖ %v", problem)
+			}
+		}
+
+		if len(problems) > 0 {
+			colorprinter.ColorPrint("error", "Found %d problem(s) in the configuration", len(problems))
+			os.Exit(1)
+		}
+
+		colorprinter.ColorPrint("success", "✔ The configuration is valid")
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		data, err := json.MarshalIndent(config.Schema(), "", "  ")
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error printing the schema: %v", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(data))
+	},
+}
+
+// getConfigProblems validates the given configuration file, or the resolved configuration along with
+// the origins of its keys.
+func getConfigProblems(args []string) ([]config.Problem, map[string]config.Origin, error) {
+	var (
+		raw     map[string]interface{}
+		origins map[string]config.Origin
+		err     error
+	)
+	if len(args) > 0 {
+		raw, err = config.LoadFile(args[0])
+	} else {
+		raw, origins, err = config.Load()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	problems := config.Validate(raw)
+	if cfg, err := config.Decode(raw); err == nil {
+		problems = append(problems, lint.ValidateRules(cfg.Rules)...)
+	}
+
+	return problems, origins, nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+
+	configMigrateCmd.Flags().BoolVarP(&migrateGlobal, "global", "g", false, "Migrate the global configuration file")
+	configShowCmd.Flags().BoolVar(&showOrigin, "origin", false, "Print the layer each value came from")
+}
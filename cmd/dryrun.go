@@ -0,0 +1,105 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the dry-run mode of the commands creating commits, which prints the commit instead of
+creating it.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"commitsense/pkg/lint"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Output formats of the dry-run mode.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var (
+	dryRun       bool
+	outputFormat string
+)
+
+// dryRunOutput is the JSON output of the dry-run mode: the commit with its message, git command line and
+// the violations of the lint rules.
+type dryRunOutput struct {
+	*commit.Commit
+	Message    string           `json:"message"`
+	Command    []string         `json:"command"`
+	Violations []lint.Violation `json:"violations"`
+}
+
+// addDryRunFlags adds the --dry-run and --output flags to the flags of a command creating commits.
+func addDryRunFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&dryRun, "dry-run", false, "Print the commit message and the git command instead of committing")
+	flags.StringVarP(&outputFormat, "output", "o", outputText, "Output format of --dry-run: text or json, json implies --dry-run")
+}
+
+// isDryRun reports whether the commit should only be printed, validating the output format.
+func isDryRun() (bool, error) {
+	switch outputFormat {
+	case outputText:
+		return dryRun, nil
+	case outputJSON:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown output format %q, expected text or json", outputFormat)
+	}
+}
+
+// printDryRun prints the commit message and the git command line that would create the commit,
+// or the commit as JSON with --output json. The violations of the lint rules are part of the JSON output
+// instead of being printed, so that the output stays valid JSON, and whether any of them is at the error
+// level is returned.
+func printDryRun(cfg *config.Config, c *commit.Commit) (bool, error) {
+	command := c.GitCommand()
+
+	if outputFormat == outputJSON {
+		violations := lint.Lint(c.Message(), cfg)
+		if violations == nil {
+			violations = []lint.Violation{}
+		}
+
+		data, err := json.MarshalIndent(dryRunOutput{
+			Commit:     c,
+			Message:    c.Message(),
+			Command:    command,
+			Violations: violations,
+		}, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		fmt.Println(string(data))
+
+		return lint.HasErrors(violations), nil
+	}
+
+	fmt.Println(c.Message())
+	fmt.Println()
+
+	quoted := make([]string, 0, len(command))
+	for _, arg := range command {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	fmt.Println(strings.Join(quoted, " "))
+
+	return false, nil
+}
+
+// shellQuote quotes the argument for a POSIX shell when it contains characters other than the safe ones.
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@,+%") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,140 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the live feedback and the validation of the commit message prompts, which run the lint
+rules of the configuration while the user types.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	"commitsense/internal/validators"
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"commitsense/pkg/lint"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	csprompt "commitsense/pkg/prompt"
+)
+
+// headerCommit returns a commit with the header fields of the commit being prompted for and the description.
+func headerCommit(c *commit.Commit, description string) *commit.Commit {
+	return &commit.Commit{
+		CommitType:        c.CommitType,
+		CommitScope:       c.CommitScope,
+		CommitDescription: description,
+		IsBreakingChange:  c.IsBreakingChange,
+	}
+}
+
+// headerViolations lints the header of the commit and returns the violations of the rules the
+// description can fix.
+func headerViolations(cfg *config.Config, c *commit.Commit) []lint.Violation {
+	var violations []lint.Violation
+	for _, violation := range lint.Lint(c.Header(), cfg) {
+		switch violation.Rule {
+		case lint.RuleHeaderMaxLength, lint.RuleSubjectCase, lint.RuleSubjectFullStop, lint.RuleSubjectForbidden:
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations
+}
+
+// descriptionFeedback returns the feedback shown while the user types the commit description: the length
+// of the header against the limit of the header-max-length rule and the violations of the header rules.
+func descriptionFeedback(cfg *config.Config, c *commit.Commit) func(string) csprompt.Feedback {
+	rule, _ := lint.LookupRule(lint.RuleHeaderMaxLength)
+	_, options := rule.Configure(cfg)
+
+	return func(description string) csprompt.Feedback {
+		header := headerCommit(c, description)
+		feedback := csprompt.Feedback{
+			Counter: fmt.Sprintf("%d/%d", utf8.RuneCountInString(header.Header()), options.Int("max")),
+		}
+		if description == "" {
+			return feedback
+		}
+
+		for _, violation := range headerViolations(cfg, header) {
+			if violation.Level == config.LevelError {
+				feedback.Errors = append(feedback.Errors, violation.Message)
+			} else {
+				feedback.Warnings = append(feedback.Warnings, violation.Message)
+			}
+		}
+
+		return feedback
+	}
+}
+
+// validateDescription returns a validator of the commit description, which rejects descriptions that
+// violate a rule of the header at the error level.
+func validateDescription(cfg *config.Config, c *commit.Commit) func(string) error {
+	return func(description string) error {
+		for _, violation := range headerViolations(cfg, headerCommit(c, description)) {
+			if violation.Level == config.LevelError {
+				return errors.New(violation.Message)
+			}
+		}
+		return nil
+	}
+}
+
+// bodyWidth returns the wrap width of the commit body, the maximum line length of the body-max-line-length
+// rule, along with the level of the rule.
+func bodyWidth(cfg *config.Config) (int, string) {
+	rule, _ := lint.LookupRule(lint.RuleBodyMaxLineLength)
+	level, options := rule.Configure(cfg)
+
+	return options.Int("max"), level
+}
+
+// bodyLineFeedback returns the feedback shown while the user types a line of the commit body: the length
+// of the line against the wrap width.
+func bodyLineFeedback(cfg *config.Config) func(string) csprompt.Feedback {
+	width, level := bodyWidth(cfg)
+
+	return func(line string) csprompt.Feedback {
+		length := utf8.RuneCountInString(line)
+		feedback := csprompt.Feedback{Counter: fmt.Sprintf("%d/%d", length, width)}
+
+		if length > width && level != config.LevelOff {
+			message := fmt.Sprintf("line must not be longer than %d characters", width)
+			if level == config.LevelError {
+				feedback.Errors = append(feedback.Errors, message)
+			} else {
+				feedback.Warnings = append(feedback.Warnings, message)
+			}
+		}
+
+		return feedback
+	}
+}
+
+// validateBodyLine returns a validator of the lines of the commit body, which rejects lines longer than
+// the wrap width when the body-max-line-length rule is at the error level.
+func validateBodyLine(cfg *config.Config) func(string) error {
+	width, level := bodyWidth(cfg)
+	if level != config.LevelError {
+		return nil
+	}
+
+	return validators.ValidateStringMaxLength(width)
+}
+
+// checkCommit lints the message of the commit before it is created and prints the violations. An error
+// is returned when the message violates a rule at the error level, so that git is never called with it.
+func checkCommit(cfg *config.Config, c *commit.Commit) error {
+	violations := lint.Lint(c.Message(), cfg)
+	printViolations("", violations)
+
+	if lint.HasErrors(violations) {
+		return errors.New("the commit message violates the lint rules")
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the hook commands, which install CommitSense as Git commit-msg and prepare-commit-msg hooks.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"commitsense/pkg/hook"
+	"commitsense/pkg/lint"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the CommitSense Git hooks",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the commit-msg and prepare-commit-msg hooks",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if !installHooks() {
+			os.Exit(1)
+		}
+	},
+}
+
+// installHooks installs the CommitSense hooks, reporting the outcome of each hook,
+// and returns whether all of them were installed.
+func installHooks() bool {
+	installed := true
+	for _, name := range hook.Names {
+		path, err := hook.Install(name)
+		if errors.Is(err, hook.ErrForeignHook) {
+			colorprinter.ColorPrint("error", "Skipped %s: %v", path, err)
+			installed = false
+			continue
+		}
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error installing the %s hook: %v", name, err)
+			return false
+		}
+
+		colorprinter.ColorPrint("success", "Installed %s", path)
+	}
+
+	return installed
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the hooks installed by CommitSense",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		for _, name := range hook.Names {
+			path, err := hook.Uninstall(name)
+			if errors.Is(err, hook.ErrNotInstalled) {
+				colorprinter.ColorPrint("info", "No %s hook installed at %s", name, path)
+				continue
+			}
+			if errors.Is(err, hook.ErrForeignHook) {
+				colorprinter.ColorPrint("info", "Left %s in place: %v", path, err)
+				continue
+			}
+			if err != nil {
+				colorprinter.ColorPrint("error", "Error removing the %s hook: %v", name, err)
+				os.Exit(1)
+			}
+
+			colorprinter.ColorPrint("success", "Removed %s", path)
+		}
+	},
+}
+
+// hookRunCmd is invoked by the installed hook scripts with the arguments Git passes to the hook.
+var hookRunCmd = &cobra.Command{
+	Use:    "run <hook> <message-file> [source] [sha]",
+	Short:  "Run a CommitSense hook, used by the installed hook scripts",
+	Hidden: true,
+	Args:   cobra.RangeArgs(2, 4),
+	Run: func(_ *cobra.Command, args []string) {
+		var err error
+		switch args[0] {
+		case hook.CommitMsg:
+			err = runCommitMsgHook(args[1])
+		case hook.PrepareCommitMsg:
+			err = runPrepareCommitMsgHook(args[1], args[2:])
+		default:
+			err = fmt.Errorf("unknown hook %q", args[0])
+		}
+
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// runCommitMsgHook validates the message git commit produced against the configuration.
+func runCommitMsgHook(messageFile string) error {
+	message, err := os.ReadFile(messageFile)
+	if err != nil {
+		return fmt.Errorf("reading the commit message: %w", err)
+	}
+
+	cfg, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("reading the configuration: %w", err)
+	}
+
+	violations := lint.Lint(commit.StripComments(string(message)), cfg)
+	printViolations("", violations)
+
+	if !lint.HasErrors(violations) {
+		return nil
+	}
+
+	return errors.New("validating the commit message: the message does not follow the configuration")
+}
+
+// runPrepareCommitMsgHook prompts for the commit message when git commit did not get one.
+// The comments git wrote into the message file are kept below the generated message.
+func runPrepareCommitMsgHook(messageFile string, gitArgs []string) error {
+	if len(gitArgs) > 0 && gitArgs[0] != "" {
+		return nil
+	}
+
+	template, err := os.ReadFile(messageFile)
+	if err != nil {
+		return fmt.Errorf("reading the commit message: %w", err)
+	}
+
+	if commit.StripComments(string(template)) != "" {
+		return nil
+	}
+
+	cfg, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("reading the configuration: %w", err)
+	}
+
+	// Nothing may be staged when committing with --all or with pathspecs, the scope is then not suggested.
+	stagedFiles, _ := commit.GetStagedFiles()
+
+	c, err := promptCommit(cfg, stagedFiles)
+	if err != nil {
+		return err
+	}
+
+	message := c.Message() + "\n" + string(template)
+
+	return os.WriteFile(messageFile, []byte(message), 0o600)
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+	hookCmd.AddCommand(hookRunCmd)
+}
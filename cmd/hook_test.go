@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"commitsense/pkg/git"
+	"commitsense/pkg/hook"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHookInstallCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		hooksPath string
+		dir       string
+	}{
+		{name: "hooks directory of the repository", dir: filepath.Join(".git", "hooks")},
+		{name: "core.hooksPath", hooksPath: ".githooks", dir: ".githooks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &git.MemoryRepository{Dir: t.TempDir(), Settings: map[string]string{"core.hooksPath": tt.hooksPath}}
+
+			executeCommand(t, repo, "hook", "install")
+
+			for _, name := range hook.Names {
+				path := filepath.Join(repo.Dir, tt.dir, name)
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("the %s hook was not installed: %v", name, err)
+				}
+				if info.Mode()&0o100 == 0 {
+					t.Errorf("the %s hook is not executable: %v", name, info.Mode())
+				}
+
+				script, _ := os.ReadFile(path)
+				if !strings.Contains(string(script), "commitsense hook run "+name) {
+					t.Errorf("the %s hook does not run commitsense:\n%s", name, script)
+				}
+			}
+		})
+	}
+}
+
+func TestInstallHooksKeepsForeignHooks(t *testing.T) {
+	repo := &git.MemoryRepository{Dir: t.TempDir()}
+	git.Use(repo)
+	t.Cleanup(func() { git.Use(nil) })
+
+	hooksDir := filepath.Join(repo.Dir, ".git", "hooks")
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	foreign := "#!/bin/sh\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, hook.CommitMsg), []byte(foreign), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if installHooks() {
+		t.Error("got all hooks installed, want the foreign commit-msg hook skipped")
+	}
+
+	if script, _ := os.ReadFile(filepath.Join(hooksDir, hook.CommitMsg)); string(script) != foreign {
+		t.Errorf("the foreign hook was overwritten:\n%s", script)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, hook.PrepareCommitMsg)); err != nil {
+		t.Errorf("the prepare-commit-msg hook was not installed: %v", err)
+	}
+}
+
+func TestHookUninstallCommand(t *testing.T) {
+	repo := &git.MemoryRepository{Dir: t.TempDir()}
+	git.Use(repo)
+	t.Cleanup(func() { git.Use(nil) })
+
+	if _, err := hook.Install(hook.CommitMsg); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := hook.Uninstall(hook.CommitMsg)
+	if err != nil {
+		t.Fatalf("got error %v removing the installed hook", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("the hook was not removed: %v", err)
+	}
+
+	if _, err := hook.Uninstall(hook.CommitMsg); !errors.Is(err, hook.ErrNotInstalled) {
+		t.Errorf("got error %v removing a hook that is not installed, want %v", err, hook.ErrNotInstalled)
+	}
+	if _, err := hook.Uninstall(hook.PrepareCommitMsg); !errors.Is(err, hook.ErrNotInstalled) {
+		t.Errorf("got error %v removing a hook that was never installed, want %v", err, hook.ErrNotInstalled)
+	}
+
+	executeCommand(t, repo, "hook", "uninstall")
+}
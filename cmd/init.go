@@ -0,0 +1,224 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the init command, which creates the configuration file of a repository.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/config"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	csprompt "commitsense/pkg/prompt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	initPreset string
+	initForce  bool
+	initYes    bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a configuration file for the repository",
+	Long: `Create a configuration file for the repository.
+
+The wizard starts from a preset and asks which commit types, scopes and skip
+ci types to use, and whether to install the CommitSense Git hooks. The
+configuration is written to commitsense.config.json in the root of the
+repository.
+
+With --yes the preset is written as it is without asking any questions.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		path := config.RepositoryFile()
+		if _, err := os.Stat(path); err == nil && !initForce {
+			colorprinter.ColorPrint("error", "Error: a configuration file already exists at %s, use --force to create a new one", path)
+			os.Exit(1)
+		}
+
+		preset, err := selectPreset()
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error %v", err)
+			os.Exit(1)
+		}
+
+		cfg := preset.Config()
+		withHooks := false
+		if !initYes {
+			withHooks, err = customizeConfig(cfg)
+			if err != nil {
+				colorprinter.ColorPrint("error", "Error %v", err)
+				os.Exit(1)
+			}
+		}
+
+		path, err = config.Write(cfg)
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error writing the configuration: %v", err)
+			os.Exit(1)
+		}
+
+		colorprinter.ColorPrint("success", "Created %s", path)
+
+		if withHooks && !installHooks() {
+			os.Exit(1)
+		}
+	},
+}
+
+// selectPreset returns the preset given with --preset, or prompts the user to select one.
+func selectPreset() (config.Preset, error) {
+	if initPreset != "" {
+		return config.LookupPreset(initPreset)
+	}
+	if initYes {
+		return config.Presets[0], nil
+	}
+
+	options := make([]string, 0, len(config.Presets))
+	for _, preset := range config.Presets {
+		options = append(options, fmt.Sprintf("%s: %s", preset.Name, preset.Description))
+	}
+
+	index, err := csprompt.Select("Select a preset", options)
+	if err != nil {
+		return config.Preset{}, fmt.Errorf("prompting for the preset: %w", err)
+	}
+
+	return config.Presets[index], nil
+}
+
+// customizeConfig asks the user which commit types, scopes and skip ci types to use,
+// and returns whether the user wants to install the Git hooks.
+func customizeConfig(cfg *config.Config) (bool, error) {
+	commitTypes, err := selectCommitTypes(cfg.CommitTypes)
+	if err != nil {
+		return false, err
+	}
+	cfg.CommitTypes = commitTypes
+
+	scopes, err := csprompt.String("Enter the commit scopes separated by commas, or leave empty to allow any scope")
+	if err != nil {
+		return false, fmt.Errorf("prompting for the scopes: %w", err)
+	}
+	for _, name := range splitList(scopes) {
+		cfg.Scopes = append(cfg.Scopes, config.Scope{Name: name})
+	}
+
+	if len(cfg.Scopes) > 0 {
+		cfg.EnforceScopes, err = csprompt.Confirm("Only allow the configured scopes", false)
+		if err != nil {
+			return false, fmt.Errorf("prompting for enforcing the scopes: %w", err)
+		}
+	}
+
+	cfg.SkipCITypes, err = selectSkipCITypes(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	withHooks, err := csprompt.Confirm("Install the commit-msg and prepare-commit-msg Git hooks", true)
+	if err != nil {
+		return false, fmt.Errorf("prompting for the hooks: %w", err)
+	}
+
+	return withHooks, nil
+}
+
+// selectCommitTypes lets the user pick the commit types of the preset to keep and add custom commit types.
+func selectCommitTypes(commitTypes []config.CommitType) ([]config.CommitType, error) {
+	items := make([]*csprompt.Item, 0, len(commitTypes))
+	for _, commitType := range commitTypes {
+		items = append(items, &csprompt.Item{
+			ID:          commitType.Name,
+			IsSelected:  true,
+			Description: commitType.Summary(),
+		})
+	}
+
+	if _, err := csprompt.MultiSelect("Select the commit types", items); err != nil {
+		return nil, fmt.Errorf("prompting for the commit types: %w", err)
+	}
+
+	var selected []config.CommitType
+	for i, commitType := range commitTypes {
+		if items[i].IsSelected {
+			selected = append(selected, commitType)
+		}
+	}
+
+	custom, err := csprompt.String("Enter additional commit types separated by commas, or leave empty")
+	if err != nil {
+		return nil, fmt.Errorf("prompting for the commit types: %w", err)
+	}
+	for _, name := range splitList(custom) {
+		selected = append(selected, config.CommitType{Name: name})
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("at least one commit type is required")
+	}
+
+	return selected, nil
+}
+
+// selectSkipCITypes lets the user pick the commit types that skip the CI run.
+func selectSkipCITypes(cfg *config.Config) ([]string, error) {
+	items := make([]*csprompt.Item, 0, len(cfg.CommitTypes))
+	for _, commitType := range cfg.CommitTypes {
+		items = append(items, &csprompt.Item{
+			ID:         commitType.Name,
+			IsSelected: contains(cfg.SkipCITypes, commitType.Name),
+		})
+	}
+
+	if _, err := csprompt.MultiSelect("Select the commit types that skip the CI run", items); err != nil {
+		return nil, fmt.Errorf("prompting for the skip ci types: %w", err)
+	}
+
+	skipCITypes := []string{}
+	for _, item := range items {
+		if item.IsSelected {
+			skipCITypes = append(skipCITypes, item.ID)
+		}
+	}
+
+	return skipCITypes, nil
+}
+
+// splitList splits a comma separated list, leaving out empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringVarP(&initPreset, "preset", "p", "", "Preset to start from: conventional, angular, gitmoji or minimal")
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Create the configuration file even if one already exists")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Write the preset without asking any questions")
+}
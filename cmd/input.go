@@ -0,0 +1,176 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the non-interactive input of the commit command: the flags setting the fields of the commit
+and the serialized commit read with --from-json.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	"bytes"
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	flagType        string
+	flagScope       string
+	flagDescription string
+	flagBody        []string
+	flagBreaking    string
+	flagCoAuthors   []string
+	flagTrailers    []string
+	fromJSON        string
+	noInput         bool
+)
+
+// fieldFlags maps the fields of the commit to the flags setting them.
+var fieldFlags = map[string]string{
+	fieldType:        "type",
+	fieldScope:       "scope",
+	fieldDescription: "description",
+	fieldBody:        "body",
+	fieldCoAuthors:   "co-author",
+	fieldBreaking:    "breaking",
+}
+
+// jsonFields maps the keys of a serialized commit to the fields of the commit.
+var jsonFields = map[string]string{
+	"type":                 fieldType,
+	"scope":                fieldScope,
+	"description":          fieldDescription,
+	"body":                 fieldBody,
+	"co_authors":           fieldCoAuthors,
+	"breaking_description": fieldBreaking,
+}
+
+// requiredFields are the fields a commit cannot be created without.
+var requiredFields = []string{fieldType, fieldDescription}
+
+// commitInput returns the commit made of the serialized commit given with --from-json and the flags, along
+// with the fields that were given. The flags take precedence over the serialized commit.
+func commitInput(flags *pflag.FlagSet, stagedFiles []commit.StagedFile) (*commit.Commit, map[string]bool, error) {
+	c := &commit.Commit{}
+	given := map[string]bool{}
+
+	if fromJSON != "" {
+		if err := readCommitJSON(fromJSON, c, given); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if flags.Changed("type") {
+		c.CommitType = flagType
+		given[fieldType] = true
+	}
+	if flags.Changed("scope") {
+		c.CommitScope = flagScope
+		given[fieldScope] = true
+	}
+	if flags.Changed("description") {
+		c.CommitDescription = flagDescription
+		given[fieldDescription] = true
+	}
+	if flags.Changed("body") {
+		c.CommitBody = strings.Join(flagBody, "\n\n")
+		given[fieldBody] = true
+	}
+	if flags.Changed("breaking") {
+		c.IsBreakingChange = true
+		c.BreakingChangeDescription = flagBreaking
+		given[fieldBreaking] = true
+	}
+	if flags.Changed("co-author") {
+		c.CoAuthors = flagCoAuthors
+		given[fieldCoAuthors] = true
+	}
+	if flags.Changed("trailer") {
+		footers, err := parseTrailers(flagTrailers)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.Footers = footers
+	}
+
+	c.IsCoAuthored = isCoAuthored || len(c.CoAuthors) > 0
+	c.IsBreakingChange = c.IsBreakingChange || isBreakingChange
+	c.StagedFiles = stagedFiles
+	c.Only = onlyPaths
+
+	return c, given, nil
+}
+
+// readCommitJSON reads a serialized commit from the file, or from stdin when the file is "-", into the
+// commit and marks the fields it sets as given.
+func readCommitJSON(file string, c *commit.Commit, given map[string]bool) error {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return fmt.Errorf("reading the commit: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("reading the commit from %s: %w", file, err)
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("reading the commit from %s: %w", file, err)
+	}
+	for key := range keys {
+		if field, ok := jsonFields[key]; ok {
+			given[field] = true
+		}
+	}
+
+	// The staged files are always read from the repository, and the files to commit only given with --only.
+	c.StagedFiles = nil
+	c.Only = nil
+
+	return nil
+}
+
+// promptMissingFields prompts the user for the fields of the commit that were not given, and returns whether
+// any field was prompted for. With --no-input the missing fields are left empty, and an error is returned
+// when a required field is missing.
+func promptMissingFields(cfg *config.Config, c *commit.Commit, given map[string]bool) (bool, error) {
+	prompted := false
+
+	for _, field := range commitFields(c) {
+		if given[field] {
+			continue
+		}
+
+		if noInput {
+			if contains(requiredFields, field) {
+				return false, fmt.Errorf("the %s is missing, give it with --%s or in --from-json", strings.ToLower(field), fieldFlags[field])
+			}
+			continue
+		}
+
+		if err := promptField(cfg, c, field); err != nil {
+			return false, err
+		}
+		prompted = true
+	}
+
+	return prompted, nil
+}
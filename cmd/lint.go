@@ -0,0 +1,121 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the lint command, which validates existing commit messages against the configuration.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"commitsense/pkg/lint"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var lintMessageFile string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [revision-range]",
+	Short: "Validate commit messages against the configuration",
+	Long: `Validate commit messages against the rules in the configuration file.
+
+Violations of rules at the error level fail the lint, violations of rules at
+the warn level are only reported.
+
+The messages to validate can be read from a file, from stdin or from the Git
+history using a revision range:
+
+  commitsense lint --file .git/COMMIT_EDITMSG
+  echo "feat: add a feature" | commitsense lint --file -
+  commitsense lint origin/main..HEAD`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		entries, err := getLintEntries(args)
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+
+		cfg, err := config.Read()
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error reading the configuration: %v", err)
+			os.Exit(1)
+		}
+
+		failed := 0
+		for _, entry := range entries {
+			violations := lint.Lint(entry.Message, cfg)
+			printViolations(shortSHA(entry.SHA)+" ", violations)
+
+			if lint.HasErrors(violations) {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			colorprinter.ColorPrint("error", "%d of %d commit messages failed the lint", failed, len(entries))
+			os.Exit(1)
+		}
+
+		colorprinter.ColorPrint("success", "✔ %d commit messages passed the lint", len(entries))
+	},
+}
+
+// printViolations prints the rule violations, errors with ✖ and warnings with ⚠, each line starting with the prefix.
+func printViolations(prefix string, violations []lint.Violation) {
+	for _, violation := range violations {
+		if violation.Level == config.LevelError {
+			colorprinter.ColorPrint("error", "✖ %s%v", prefix, violation)
+		} else {
+			colorprinter.ColorPrint("info", "⚠ %s%v", prefix, violation)
+		}
+	}
+}
+
+// getLintEntries returns the commit messages to lint based on the given flags and arguments. The comments are
+// stripped from the messages read from a file or stdin, as git does before committing, while the messages in
+// the history are already cleaned and their lines starting with '#' are content.
+func getLintEntries(args []string) ([]commit.LogEntry, error) {
+	switch {
+	case lintMessageFile != "" && len(args) > 0:
+		return nil, errors.New("use either --file or a revision range, not both")
+	case lintMessageFile == "-":
+		message, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return []commit.LogEntry{{SHA: "stdin", Message: commit.StripComments(string(message))}}, nil
+	case lintMessageFile != "":
+		message, err := os.ReadFile(lintMessageFile)
+		if err != nil {
+			return nil, err
+		}
+		return []commit.LogEntry{{SHA: lintMessageFile, Message: commit.StripComments(string(message))}}, nil
+	case len(args) == 1:
+		return commit.GetCommitLog(args[0])
+	default:
+		return nil, errors.New("give a revision range or a message file with --file")
+	}
+}
+
+// shortSHA abbreviates commit SHAs, leaving file names and other labels untouched.
+func shortSHA(sha string) string {
+	const shortLength = 7
+	if len(sha) == 40 {
+		return sha[:shortLength]
+	}
+	return sha
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&lintMessageFile, "file", "f", "", "Read the commit message from a file, use - for stdin")
+}
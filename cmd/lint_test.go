@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"commitsense/pkg/git"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetLintEntriesStripsCommentsOnlyFromFiles(t *testing.T) {
+	message := "fix: Handle empty pages\n\n#123 follow-up"
+	repo := &git.MemoryRepository{Dir: t.TempDir(), History: []git.LogEntry{{SHA: "aaaa", Message: message}}}
+	git.Use(repo)
+	t.Cleanup(func() { git.Use(nil) })
+
+	entries, err := getLintEntries([]string{"HEAD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Message != message {
+		t.Errorf("got entries %+v from the history, want the message %q", entries, message)
+	}
+
+	path := filepath.Join(repo.Dir, "COMMIT_EDITMSG")
+	if err := os.WriteFile(path, []byte(message+"\n# Please enter the commit message.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lintMessageFile = path
+	t.Cleanup(func() { lintMessageFile = "" })
+
+	entries, err = getLintEntries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "fix: Handle empty pages"; len(entries) != 1 || entries[0].Message != want {
+		t.Errorf("got entries %+v from the file, want the message %q", entries, want)
+	}
+}
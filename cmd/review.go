@@ -0,0 +1,149 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the review step of the commit command, which shows the commit message before the commit is
+created and lets the user edit it.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"commitsense/pkg/editor"
+	"commitsense/pkg/lint"
+	"errors"
+	"fmt"
+	"strings"
+
+	csprompt "commitsense/pkg/prompt"
+)
+
+// Options of the review step.
+const (
+	reviewConfirm = "Create the commit"
+	reviewEdit    = "Edit the message in the editor"
+	reviewRestart = "Change a field"
+	reviewAbort   = "Abort"
+)
+
+var reviewOptions = []string{reviewConfirm, reviewEdit, reviewRestart, reviewAbort}
+
+// editHelp is appended to the commit message opened in the editor.
+const editHelp = `# Edit the commit message. Lines starting with '#' are ignored, and the
+# message is checked against the lint rules before the commit is created.`
+
+// errCommitAborted is returned by reviewCommit when the user aborts the commit.
+var errCommitAborted = errors.New("the commit was aborted")
+
+// reviewCommit shows the commit message with the lint rule violations, and lets the user create the commit,
+// edit the message in the editor, prompt for one of the fields again or abort the commit. The commit
+// cannot be created while the message violates a rule at the error level.
+func reviewCommit(cfg *config.Config, c *commit.Commit) (*commit.Commit, error) {
+	for {
+		message := c.Message()
+		violations := lint.Lint(message, cfg)
+
+		fmt.Println()
+		printMessage(message)
+		fmt.Println()
+		printViolations("", violations)
+
+		index, err := csprompt.Select("Review the commit message", reviewOptions)
+		if err != nil {
+			return nil, fmt.Errorf("prompting for the review: %w", err)
+		}
+
+		switch reviewOptions[index] {
+		case reviewConfirm:
+			if !lint.HasErrors(violations) {
+				return c, nil
+			}
+			colorprinter.ColorPrint("error", "Fix the violations of the lint rules before creating the commit")
+		case reviewEdit:
+			edited, err := editCommit(c)
+			if err != nil {
+				colorprinter.ColorPrint("error", "Error editing the commit message: %v", err)
+				continue
+			}
+			c = edited
+		case reviewRestart:
+			fields := commitFields(c)
+			index, err := csprompt.Select("Select the field to change", fields)
+			if err != nil {
+				return nil, fmt.Errorf("prompting for the field: %w", err)
+			}
+			if err := promptField(cfg, c, fields[index]); err != nil {
+				return nil, err
+			}
+		case reviewAbort:
+			return nil, errCommitAborted
+		}
+	}
+}
+
+// editCommit opens the commit message in the editor and parses the edited message into a commit.
+func editCommit(c *commit.Commit) (*commit.Commit, error) {
+	edited, err := editor.Edit(c.Message() + "\n\n" + editHelp + "\n")
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := commit.ParseCommitMessage(commit.StripComments(edited))
+	if err != nil {
+		return nil, err
+	}
+	// The message does not record which files to commit.
+	parsed.StagedFiles = c.StagedFiles
+	parsed.Only = c.Only
+
+	return parsed, nil
+}
+
+// printMessage prints the commit message highlighting the header, the body and the footers.
+func printMessage(message string) {
+	lines := strings.Split(message, "\n")
+	footerStart := findFooterStart(lines)
+
+	for i, line := range lines {
+		variant := "stdout"
+		switch {
+		case i == 0:
+			variant = "bold"
+		case line == commit.SkipCIMarker:
+			variant = "info"
+		case i >= footerStart && (strings.HasPrefix(line, "BREAKING CHANGE") || strings.HasPrefix(line, "BREAKING-CHANGE")):
+			variant = "error"
+		case i >= footerStart:
+			variant = "info"
+		}
+
+		colorprinter.ColorPrint(variant, "  %s", line)
+	}
+}
+
+// findFooterStart returns the index of the first line of the footers, the trailing paragraphs of the
+// message that start with a git trailer, or the number of lines when there are no footers.
+func findFooterStart(lines []string) int {
+	footerStart := len(lines)
+
+	for footerStart > 1 {
+		end := footerStart
+		for end > 1 && lines[end-1] == "" {
+			end--
+		}
+		start := end
+		for start > 1 && lines[start-1] != "" {
+			start--
+		}
+
+		if start == end || !commit.IsFooter(lines[start]) {
+			break
+		}
+		footerStart = start
+	}
+
+	return footerStart
+}
@@ -11,15 +11,23 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/config"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var validArgs = []string{"commit", "help"}
 
+var (
+	configPath     string
+	configSettings []string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "commitsense",
 	Short: "A tool to improve commit messages",
@@ -32,6 +40,12 @@ files and create commit messages following the Conventional Commits specificatio
 	DisableSuggestions: false,
 	Args:               cobra.OnlyValidArgs,
 	ValidArgs:          validArgs,
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		if err := config.SetFlags(configPath, configSettings); err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+	},
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		return cmd.Help()
 	},
@@ -44,10 +58,40 @@ func SetVersion(version string, date string) {
 
 func init() {
 	cobra.OnInitialize()
+
+	addConfigFlags(rootCmd.PersistentFlags(), &configPath, &configSettings)
+}
+
+func addConfigFlags(flags *pflag.FlagSet, path *string, settings *[]string) {
+	flags.StringVar(path, "config", "", "Configuration file overriding the other configuration files")
+	flags.StringArrayVar(settings, "set", nil, "Configuration value as key=value, overriding the configuration files")
+}
+
+// applyConfigFlags applies the configuration flags before the command line is parsed,
+// as the configuration decides which shorthand commands there are.
+func applyConfigFlags(args []string) {
+	var path string
+	var settings []string
+
+	flags := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.SetOutput(io.Discard)
+	flags.BoolP("help", "h", false, "")
+	addConfigFlags(flags, &path, &settings)
+
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+
+	// Invalid settings are reported once the command line is parsed.
+	_ = config.SetFlags(path, settings)
 }
 
 // Execute command for the root command
 func Execute() {
+	applyConfigFlags(os.Args[1:])
+	registerShorthandCommands()
+
 	if err := rootCmd.Execute(); err != nil {
 		colorprinter.ColorPrint("error", "Error while executing: %v", err)
 
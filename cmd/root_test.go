@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"commitsense/pkg/git"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// executeCommand runs the command line against the repository and returns what the command printed with
+// fmt to stdout. The flags of the command are reset afterwards, as cobra keeps them between executions.
+func executeCommand(t *testing.T, repo git.Repository, args ...string) string {
+	t.Helper()
+
+	// The global configuration of the user must not affect the commands.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	git.Use(repo)
+	t.Cleanup(func() { git.Use(nil) })
+
+	cmd, _, err := rootCmd.Find(args)
+	if err != nil {
+		t.Fatalf("finding the command of %q: %v", args, err)
+	}
+	t.Cleanup(func() {
+		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if slice, ok := f.Value.(pflag.SliceValue); ok {
+				_ = slice.Replace(nil)
+			} else {
+				_ = f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+	})
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+
+	output := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(reader)
+		output <- string(out)
+	}()
+
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+
+	writer.Close()
+	os.Stdout = stdout
+
+	if err != nil {
+		t.Fatalf("executing %q: %v", args, err)
+	}
+	return <-output
+}
@@ -15,6 +15,8 @@ package cmd
 import (
 	colorprinter "commitsense/internal/printer"
 	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -23,50 +25,181 @@ import (
 )
 
 var (
-	commitScope    string
-	breakingChange bool
+	commitScope         string
+	noScope             bool
+	breakingChange      bool
+	breakingDescription string
+	bodyParagraphs      []string
+	coAuthors           []string
+	trailers            []string
 )
 
-func newShorthandCommand(commitType string) *cobra.Command {
-	shorthandCmd := &cobra.Command{
-		Use:   commitType + " [message]",
-		Short: fmt.Sprintf("Create a git commit with type %s", commitType),
-		Args:  cobra.MinimumNArgs(1),
-		Run: func(_ *cobra.Command, args []string) {
-			stagedFiles, err := commit.GetStagedFiles()
-			if err != nil {
-				colorprinter.ColorPrint("error", "Error: %v", err)
-				os.Exit(1)
-			}
+func newShorthandCommand(t config.CommitType) *cobra.Command {
+	commitType := t.Name
 
-			commitDescription := strings.Join(args, " ")
-
-			c := commit.Commit{
-				CommitType:        commitType,
-				CommitScope:       commitScope,
-				CommitDescription: commitDescription,
-				IsCoAuthored:      isCoAuthored,
-				IsBreakingChange:  breakingChange,
-				StagedFiles:       stagedFiles,
-			}
+	short := fmt.Sprintf("Create a git commit with type %s", commitType)
+	if summary := t.Summary(); summary != "" {
+		short = fmt.Sprintf("%s (%s)", summary, commitType)
+	}
 
-			if err := c.CreateGitCommit(); err != nil {
-				colorprinter.ColorPrint("error", "Error creating a commit: %v", err)
-				os.Exit(1)
-			}
+	shorthandCmd := &cobra.Command{
+		Use:     commitType + " [message]",
+		Short:   short,
+		Aliases: t.Aliases,
+		Hidden:  t.Hidden,
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			runShorthandCommand(commitType, strings.Join(args, " "))
 		},
 	}
 
-	shorthandCmd.Flags().StringVarP(&commitScope, "scope", "s", "", "Commit scope")
+	shorthandCmd.Flags().StringVarP(&commitScope, "scope", "s", "", "Commit scope, by default the scope whose paths match the files")
+	shorthandCmd.Flags().BoolVar(&noScope, "no-scope", false, "Commit without a scope")
 	shorthandCmd.Flags().BoolVarP(&breakingChange, "is-breaking", "b", false, "Commit is introducing a breaking change")
+	shorthandCmd.Flags().StringVarP(&breakingDescription, "breaking-description", "B", "", "Description of the breaking change, implies --is-breaking")
+	shorthandCmd.Flags().StringArrayVarP(&bodyParagraphs, "message", "m", nil, "Paragraph of the commit body, can be repeated")
+	shorthandCmd.Flags().StringArrayVarP(&coAuthors, "co-author", "c", nil, `Co-author of the commit as "Name <email>", can be repeated`)
+	shorthandCmd.Flags().StringArrayVarP(&trailers, "trailer", "t", nil, `Trailer of the commit as "Token: value", can be repeated`)
+	shorthandCmd.Flags().StringArrayVar(&onlyPaths, "only", nil, "Commit only the current content of the file instead of the index, can be repeated")
+	shorthandCmd.MarkFlagsMutuallyExclusive("scope", "no-scope")
+	addDryRunFlags(shorthandCmd.Flags())
 
 	return shorthandCmd
 }
 
-func init() {
-	commitTypes := []string{"build", "ci", "chore", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+// runShorthandCommand creates the commit of the shorthand command, or prints it in a dry run.
+func runShorthandCommand(commitType string, description string) {
+	cfg, err := config.Read()
+	if err != nil {
+		colorprinter.ColorPrint("error", "Error reading the configuration: %v", err)
+		os.Exit(1)
+	}
+
+	printOnly, err := isDryRun()
+	if err != nil {
+		colorprinter.ColorPrint("error", "Error: %v", err)
+		os.Exit(1)
+	}
+
+	c, err := shorthandCommit(cfg, commitType, description, printOnly)
+	if err != nil {
+		colorprinter.ColorPrint("error", "Error: %v", err)
+		os.Exit(1)
+	}
+
+	// A dry run fails the same way as committing would. With --output json the violations are part
+	// of the output instead.
+	if outputFormat != outputJSON {
+		if err := checkCommit(cfg, c); err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	if printOnly {
+		failed, err := printDryRun(cfg, c)
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := c.CreateGitCommit(); err != nil {
+		colorprinter.ColorPrint("error", "Error creating a commit: %v", err)
+		os.Exit(1)
+	}
+}
+
+// shorthandCommit builds the commit of the shorthand command from its flags. Without --scope the scope
+// is the configured scope whose paths match the files, unless --no-scope is given.
+func shorthandCommit(cfg *config.Config, commitType string, description string, printOnly bool) (*commit.Commit, error) {
+	// With --only the files are committed from the working tree, nothing needs to be staged. Files with
+	// unresolved conflicts cannot be committed either way.
+	stagedFiles, err := commit.GetStagedFiles()
+	var unmerged *commit.UnmergedError
+	if err != nil && (errors.As(err, &unmerged) || (!printOnly && len(onlyPaths) == 0)) {
+		return nil, err
+	}
+
+	footers, err := parseTrailers(trailers)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &commit.Commit{
+		CommitType:                commitType,
+		CommitScope:               commitScope,
+		CommitDescription:         description,
+		CommitBody:                strings.Join(bodyParagraphs, "\n\n"),
+		IsCoAuthored:              len(coAuthors) > 0,
+		CoAuthors:                 coAuthors,
+		IsBreakingChange:          breakingChange || breakingDescription != "",
+		BreakingChangeDescription: breakingDescription,
+		Footers:                   footers,
+		StagedFiles:               stagedFiles,
+		Only:                      onlyPaths,
+	}
+
+	if c.CommitScope == "" && !noScope && cfg.ScopeMode(commitType) != config.ScopeForbidden {
+		c.CommitScope = cfg.MatchScope(c.Paths())
+	}
+
+	if err := cfg.ValidateScope(commitType, c.CommitScope); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// parseTrailers parses the trailers given on the command line into commit footers.
+func parseTrailers(values []string) ([]commit.Footer, error) {
+	footers := make([]commit.Footer, 0, len(values))
+	for _, value := range values {
+		footer, err := commit.ParseFooter(value)
+		if err != nil {
+			return nil, err
+		}
+		footers = append(footers, footer)
+	}
+	return footers, nil
+}
+
+// registerShorthandCommands adds a shorthand command for each of the configured commit types, falling
+// back to the default commit types when the configuration cannot be read. Commit types and aliases
+// clashing with the built-in commands are skipped.
+func registerShorthandCommands() {
+	cfg, err := config.Read()
+	if err != nil {
+		cfg = config.NewDefault()
+	}
+
+	taken := map[string]bool{"help": true, "completion": true}
+	for _, cmd := range rootCmd.Commands() {
+		taken[cmd.Name()] = true
+		for _, alias := range cmd.Aliases {
+			taken[alias] = true
+		}
+	}
+
+	for _, commitType := range cfg.CommitTypes {
+		if commitType.Name == "" || taken[commitType.Name] {
+			continue
+		}
+		taken[commitType.Name] = true
+
+		var aliases []string
+		for _, alias := range commitType.Aliases {
+			if !taken[alias] {
+				aliases = append(aliases, alias)
+				taken[alias] = true
+			}
+		}
+		commitType.Aliases = aliases
 
-	for _, commitType := range commitTypes {
 		rootCmd.AddCommand(newShorthandCommand(commitType))
 	}
 }
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"commitsense/pkg/git"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShorthandCommandDryRun(t *testing.T) {
+	repo := &git.MemoryRepository{
+		Dir:   t.TempDir(),
+		Files: []git.FileStatus{{Path: "a.go", Index: 'M', Worktree: ' '}},
+	}
+	git.Use(repo)
+	registerShorthandCommands()
+
+	output := executeCommand(t, repo, "fix", "Handle empty pages", "--dry-run")
+	if want := "fix: Handle empty pages\n\ngit commit -m 'fix: Handle empty pages'\n"; output != want {
+		t.Errorf("got output %q, want %q", output, want)
+	}
+
+	output = executeCommand(t, repo, "fix", "Handle empty pages", "--only", "a.go", "--output", "json")
+	var got dryRunOutput
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("got invalid JSON %q: %v", output, err)
+	}
+	if got.Message != "fix: Handle empty pages" || len(got.Violations) != 0 {
+		t.Errorf("got message %q with violations %v, want the message without violations", got.Message, got.Violations)
+	}
+	if len(got.Command) != 7 || got.Command[6] != "a.go" {
+		t.Errorf("got command %q, want it to commit only a.go", got.Command)
+	}
+
+	if len(repo.History) != 0 {
+		t.Errorf("the dry run created commits: %+v", repo.History)
+	}
+}
+
+func TestShorthandCommitWithUnmergedFiles(t *testing.T) {
+	repo := &git.MemoryRepository{
+		Dir: t.TempDir(),
+		Files: []git.FileStatus{
+			{Path: "a.go", Index: 'M', Worktree: ' '},
+			{Path: "b.go", Index: 'U', Worktree: 'U'},
+		},
+	}
+	git.Use(repo)
+	t.Cleanup(func() { git.Use(nil) })
+
+	_, err := shorthandCommit(config.NewDefault(), "fix", "Handle empty pages", false)
+
+	var unmerged *commit.UnmergedError
+	if !errors.As(err, &unmerged) || len(unmerged.Paths) != 1 || unmerged.Paths[0] != "b.go" {
+		t.Errorf("got error %v, want an unmerged error for b.go", err)
+	}
+}
+
+func TestShorthandCommandScope(t *testing.T) {
+	repo := &git.MemoryRepository{
+		Dir:   t.TempDir(),
+		Files: []git.FileStatus{{Path: "pkg/api/server.go", Index: 'M', Worktree: ' '}},
+	}
+	git.Use(repo)
+	registerShorthandCommands()
+
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		want   string
+	}{
+		{
+			name:   "no scope from the top-level directory",
+			config: `{}`,
+			want:   "fix: Handle empty pages",
+		},
+		{
+			name:   "scope from the configured paths",
+			config: `{"scopes": [{"name": "api", "paths": ["pkg/api/**"]}]}`,
+			want:   "fix(api): Handle empty pages",
+		},
+		{
+			name:   "no scope with --no-scope",
+			config: `{"scopes": [{"name": "api", "paths": ["pkg/api/**"]}]}`,
+			args:   []string{"--no-scope"},
+			want:   "fix: Handle empty pages",
+		},
+		{
+			name:   "scope given with --scope",
+			config: `{"scopes": [{"name": "api", "paths": ["pkg/api/**"]}]}`,
+			args:   []string{"--scope", "server"},
+			want:   "fix(server): Handle empty pages",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(repo.Dir, "commitsense.config.json")
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = os.Remove(path) })
+
+			args := append([]string{"fix", "Handle empty pages", "--output", "json"}, tt.args...)
+			var got dryRunOutput
+			if err := json.Unmarshal([]byte(executeCommand(t, repo, args...)), &got); err != nil {
+				t.Fatalf("got invalid JSON: %v", err)
+			}
+			if got.Message != tt.want {
+				t.Errorf("got message %q, want %q", got.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterShorthandCommandsHidesHiddenTypes(t *testing.T) {
+	repo := &git.MemoryRepository{Dir: t.TempDir()}
+	content := `{"commit_types": ["feat", {"name": "wip", "aliases": ["tmp"], "hidden": true}]}`
+	if err := os.WriteFile(filepath.Join(repo.Dir, "commitsense.config.json"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	git.Use(repo)
+	t.Cleanup(func() { git.Use(nil) })
+
+	registerShorthandCommands()
+
+	for name, hidden := range map[string]bool{"feat": false, "wip": true, "tmp": true} {
+		cmd, _, err := rootCmd.Find([]string{name})
+		if err != nil || cmd == rootCmd {
+			t.Fatalf("got no command for %q (error %v)", name, err)
+		}
+		if cmd.Hidden != hidden {
+			t.Errorf("got hidden %v for %q, want %v", cmd.Hidden, name, hidden)
+		}
+	}
+}
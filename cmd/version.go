@@ -0,0 +1,162 @@
+/*
+Package cmd provides commands for the commitsense application.
+
+This file contains the version commands, which calculate the next semantic version from the commit history.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package cmd
+
+import (
+	colorprinter "commitsense/internal/printer"
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"commitsense/pkg/semver"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	versionChannel   string
+	versionCreateTag bool
+	versionMajorZero bool
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version of CommitSense",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		fmt.Println(rootCmd.Version)
+	},
+}
+
+var versionNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Print the next semantic version of the repository",
+	Long: `Calculate the next semantic version from the commits made since the latest
+semver tag. Breaking changes bump the major version, and the other commit types
+bump the version as configured in commit_types[].bump, by default feat bumps the
+minor version and fix and perf bump the patch version.
+
+While the major version is 0, breaking changes only bump the minor version, as
+the initial development phase is not expected to be stable. Give --major to
+bump the major version, e.g. to release 1.0.0.
+
+  commitsense version next
+  commitsense version next --channel rc
+  commitsense version next --tag
+  commitsense version next --major`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		cfg, err := config.Read()
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error reading the configuration: %v", err)
+			os.Exit(1)
+		}
+
+		versions, err := getVersionTags()
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+
+		latest, found := semver.Latest(versions, false)
+		revisionRange := "HEAD"
+		if found {
+			// The tag itself is used, as the version drops the build metadata of e.g. v1.2.0+build.5.
+			revisionRange = latest.Tag + "..HEAD"
+		} else {
+			latest = semver.Version{Prefix: "v"}
+		}
+
+		commits, err := commit.GetCommitLog(revisionRange)
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+
+		bump, err := getVersionBump(commits, cfg)
+		if err != nil {
+			colorprinter.ColorPrint("error", "Error: %v", err)
+			os.Exit(1)
+		}
+
+		if bump == semver.BumpNone {
+			colorprinter.ColorPrint("info", "No changes requiring a release since %v", latest)
+			fmt.Println(latest)
+			return
+		}
+
+		if bump == semver.BumpMajor && latest.Major == 0 && !versionMajorZero {
+			bump = semver.BumpMinor
+		}
+
+		next := semver.Next(latest, bump, versionChannel, versions)
+		fmt.Println(next)
+
+		if versionCreateTag {
+			if err := commit.CreateTag(next.String(), "Release "+next.String()); err != nil {
+				colorprinter.ColorPrint("error", "Error creating the tag: %v", err)
+				os.Exit(1)
+			}
+			colorprinter.ColorPrint("success", "Created tag %v", next)
+		}
+	},
+}
+
+// getVersionTags returns the tags reachable from HEAD that are semantic versions.
+func getVersionTags() ([]semver.Version, error) {
+	tags, err := commit.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []semver.Version
+	for _, tag := range tags {
+		if version, err := semver.Parse(tag); err == nil {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, nil
+}
+
+// getVersionBump returns the highest version bump caused by the commits.
+// Commits that do not follow the Conventional Commits format do not bump the version.
+func getVersionBump(commits []commit.LogEntry, cfg *config.Config) (semver.Bump, error) {
+	bump := semver.BumpNone
+
+	for _, entry := range commits {
+		c, err := commit.ParseCommitMessage(entry.Message)
+		if err != nil {
+			continue
+		}
+
+		commitBump := semver.BumpMajor
+		if !c.IsBreakingChange {
+			commitBump, err = semver.ParseBump(cfg.BumpLevel(c.CommitType))
+			if err != nil {
+				return semver.BumpNone, fmt.Errorf("commit_types[].bump of %q: %w", c.CommitType, err)
+			}
+		}
+
+		if commitBump > bump {
+			bump = commitBump
+		}
+	}
+
+	return bump, nil
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.AddCommand(versionNextCmd)
+
+	versionNextCmd.Flags().StringVarP(&versionChannel, "channel", "c", "", "Pre-release channel, e.g. rc or beta")
+	versionNextCmd.Flags().BoolVarP(&versionCreateTag, "tag", "t", false, "Create an annotated tag for the next version")
+	versionNextCmd.Flags().BoolVar(&versionMajorZero, "major", false, "Bump the major version for breaking changes also while it is 0")
+}
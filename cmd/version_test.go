@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"commitsense/pkg/git"
+	"reflect"
+	"testing"
+)
+
+func TestVersionNextCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []git.LogEntry
+		tags    []git.MemoryTag
+		args    []string
+		want    string
+	}{
+		{
+			name:    "no tags",
+			history: []git.LogEntry{{SHA: "aaaa", Message: "feat: Add pages"}},
+			want:    "v0.1.0\n",
+		},
+		{
+			name: "feature since the latest tag",
+			history: []git.LogEntry{
+				{SHA: "cccc", Message: "feat: Add pages"},
+				{SHA: "bbbb", Message: "fix: Handle errors"},
+				{SHA: "aaaa", Message: "feat!: Drop v1"},
+			},
+			tags: []git.MemoryTag{{Name: "v1.2.0", SHA: "bbbb"}, {Name: "v1.0.0", SHA: "aaaa"}},
+			want: "v1.3.0\n",
+		},
+		{
+			name: "fix since the latest tag",
+			history: []git.LogEntry{
+				{SHA: "bbbb", Message: "fix: Handle errors"},
+				{SHA: "aaaa", Message: "feat: Add pages"},
+			},
+			tags: []git.MemoryTag{{Name: "v1.2.0", SHA: "aaaa"}},
+			want: "v1.2.1\n",
+		},
+		{
+			name: "tag with build metadata",
+			history: []git.LogEntry{
+				{SHA: "bbbb", Message: "feat: Add pages"},
+				{SHA: "aaaa", Message: "chore: Initial commit"},
+			},
+			tags: []git.MemoryTag{{Name: "v1.2.0+build.5", SHA: "aaaa"}},
+			want: "v1.3.0\n",
+		},
+		{
+			name: "breaking change since the latest tag",
+			history: []git.LogEntry{
+				{SHA: "bbbb", Message: "refactor: Rename the config\n\nBREAKING CHANGE: the config is renamed"},
+				{SHA: "aaaa", Message: "feat: Add pages"},
+			},
+			tags: []git.MemoryTag{{Name: "v1.2.0", SHA: "aaaa"}},
+			want: "v2.0.0\n",
+		},
+		{
+			name: "breaking change while the major version is 0",
+			history: []git.LogEntry{
+				{SHA: "bbbb", Message: "feat!: Rename the config"},
+				{SHA: "aaaa", Message: "feat: Add pages"},
+			},
+			tags: []git.MemoryTag{{Name: "v0.4.1", SHA: "aaaa"}},
+			want: "v0.5.0\n",
+		},
+		{
+			name: "breaking change while the major version is 0 with --major",
+			history: []git.LogEntry{
+				{SHA: "bbbb", Message: "feat!: Rename the config"},
+				{SHA: "aaaa", Message: "feat: Add pages"},
+			},
+			tags: []git.MemoryTag{{Name: "v0.4.1", SHA: "aaaa"}},
+			args: []string{"--major"},
+			want: "v1.0.0\n",
+		},
+		{
+			name: "release candidate",
+			history: []git.LogEntry{
+				{SHA: "cccc", Message: "feat: Add pages"},
+				{SHA: "bbbb", Message: "fix: Handle errors"},
+				{SHA: "aaaa", Message: "chore: Initial commit"},
+			},
+			tags: []git.MemoryTag{{Name: "v1.3.0-rc.1", SHA: "bbbb"}, {Name: "v1.2.0", SHA: "aaaa"}},
+			args: []string{"--channel", "rc"},
+			want: "v1.3.0-rc.2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &git.MemoryRepository{Dir: t.TempDir(), History: tt.history, TagRefs: tt.tags}
+
+			output := executeCommand(t, repo, append([]string{"version", "next"}, tt.args...)...)
+
+			if output != tt.want {
+				t.Errorf("got %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionNextCommandCreatesTag(t *testing.T) {
+	repo := &git.MemoryRepository{
+		Dir:     t.TempDir(),
+		History: []git.LogEntry{{SHA: "bbbb", Message: "feat: Add pages"}, {SHA: "aaaa", Message: "chore: Initial commit"}},
+		TagRefs: []git.MemoryTag{{Name: "v1.0.0", SHA: "aaaa"}},
+	}
+
+	executeCommand(t, repo, "version", "next", "--tag")
+
+	want := []git.MemoryTag{{Name: "v1.1.0", SHA: "bbbb", Message: "Release v1.1.0"}, {Name: "v1.0.0", SHA: "aaaa"}}
+	if !reflect.DeepEqual(repo.TagRefs, want) {
+		t.Errorf("got tags %+v, want %+v", repo.TagRefs, want)
+	}
+}
@@ -11,7 +11,10 @@ Copyright © 2023 HENRI REMONEN <henri@remonen.fi>
 */
 package validators
 
-import "fmt"
+import (
+	"fmt"
+	"unicode/utf8"
+)
 
 // ValidateStringNotEmpty checks if a string is not empty.
 func ValidateStringNotEmpty(s string) error {
@@ -28,3 +31,13 @@ func ValidateStringYesNo(s string) error {
 	}
 	return fmt.Errorf("please enter Y or N")
 }
+
+// ValidateStringMaxLength returns a validator checking that a string is at most max characters long.
+func ValidateStringMaxLength(max int) func(string) error {
+	return func(s string) error {
+		if length := utf8.RuneCountInString(s); length > max {
+			return fmt.Errorf("please enter at most %d characters, got %d", max, length)
+		}
+		return nil
+	}
+}
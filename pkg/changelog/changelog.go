@@ -0,0 +1,166 @@
+/*
+Package changelog provides functionality for generating changelogs from the Conventional Commits history.
+
+This package groups commits by their commit type and scope, lists the breaking changes first and renders
+the result as Markdown in the Keep a Changelog style. The section titles of the commit types are read from
+the CommitSense configuration.
+
+Usage:
+  - Call the Generate function with the commits of a release to render the release section.
+  - Call the Prepend function to add a release section on top of an existing changelog.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package changelog
+
+import (
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	breakingChangesTitle = "⚠ BREAKING CHANGES"
+	shortSHALength       = 7
+)
+
+// Header is the introduction written at the top of a new changelog file.
+const Header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and the commit messages follow [Conventional Commits](https://www.conventionalcommits.org/en/v1.0.0/).
+`
+
+// Release represents a version of the project and the commits it consists of.
+type Release struct {
+	// Title is the version of the release, e.g. "1.2.0" or "Unreleased".
+	Title string
+	// Date is the release date, left out of the heading when zero.
+	Date    time.Time
+	Commits []commit.LogEntry
+}
+
+type entry struct {
+	sha    string
+	commit *commit.Commit
+}
+
+// Generate renders the release as a Markdown changelog section. Commits that do not follow the
+// Conventional Commits format, and commits of types without a changelog title, are left out.
+func Generate(release Release, cfg *config.Config) string {
+	var breaking []entry
+	sections := map[string][]entry{}
+
+	for _, logEntry := range release.Commits {
+		c, err := commit.ParseCommitMessage(logEntry.Message)
+		if err != nil {
+			continue
+		}
+
+		e := entry{sha: logEntry.SHA, commit: c}
+		if c.IsBreakingChange {
+			breaking = append(breaking, e)
+		}
+		if _, ok := cfg.ChangelogTitle(c.CommitType); ok {
+			sections[c.CommitType] = append(sections[c.CommitType], e)
+		}
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("## [" + release.Title + "]")
+	if !release.Date.IsZero() {
+		sb.WriteString(" - " + release.Date.Format("2006-01-02"))
+	}
+	sb.WriteString("\n")
+
+	if len(breaking) > 0 {
+		writeSection(&sb, breakingChangesTitle, breaking, breakingChangeText)
+	}
+
+	for _, commitType := range sectionOrder(cfg, sections) {
+		title, _ := cfg.ChangelogTitle(commitType)
+		writeSection(&sb, title, sections[commitType], func(c *commit.Commit) string {
+			return c.CommitDescription
+		})
+	}
+
+	return sb.String()
+}
+
+// Prepend adds the release section on top of the releases in an existing changelog,
+// keeping the introduction of the changelog in place. An empty changelog gets the default header.
+func Prepend(existing string, section string) string {
+	if strings.TrimSpace(existing) == "" {
+		return Header + "\n" + section
+	}
+
+	lines := strings.SplitAfter(existing, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			return strings.Join(lines[:i], "") + section + "\n" + strings.Join(lines[i:], "")
+		}
+	}
+
+	return strings.TrimRight(existing, "\n") + "\n\n" + section
+}
+
+// sectionOrder returns the commit types with entries in the order they are configured,
+// followed by the types missing from the configuration in alphabetical order.
+func sectionOrder(cfg *config.Config, sections map[string][]entry) []string {
+	var order []string
+	seen := map[string]bool{}
+
+	for _, commitType := range cfg.TypeNames() {
+		if _, ok := sections[commitType]; ok && !seen[commitType] {
+			order = append(order, commitType)
+			seen[commitType] = true
+		}
+	}
+
+	var rest []string
+	for commitType := range sections {
+		if !seen[commitType] {
+			rest = append(rest, commitType)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(order, rest...)
+}
+
+// writeSection writes a section of entries grouped by scope, unscoped entries first.
+func writeSection(sb *strings.Builder, title string, entries []entry, text func(*commit.Commit) string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].commit.CommitScope < entries[j].commit.CommitScope
+	})
+
+	sb.WriteString("\n### " + title + "\n\n")
+	for _, e := range entries {
+		sb.WriteString("- ")
+		if e.commit.CommitScope != "" {
+			sb.WriteString("**" + e.commit.CommitScope + ":** ")
+		}
+		sb.WriteString(strings.ReplaceAll(text(e.commit), "\n", " "))
+		sb.WriteString(fmt.Sprintf(" (%s)\n", shortSHA(e.sha)))
+	}
+}
+
+func breakingChangeText(c *commit.Commit) string {
+	if c.BreakingChangeDescription != "" {
+		return c.BreakingChangeDescription
+	}
+	return c.CommitDescription
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > shortSHALength {
+		return sha[:shortSHALength]
+	}
+	return sha
+}
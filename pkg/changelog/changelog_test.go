@@ -0,0 +1,106 @@
+package changelog
+
+import (
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+
+	return string(content)
+}
+
+// history is the log of a release, newest commit first.
+var history = []commit.LogEntry{
+	{SHA: "a1a1a1a1a1a1a1a1", Message: "feat(api): Add pagination"},
+	{SHA: "b2b2b2b2b2b2b2b2", Message: "fix: Handle empty pages"},
+	{SHA: "c3c3c3c3c3c3c3c3", Message: "feat: Add the login page\n\nThe body is not part of the changelog."},
+	{SHA: "d4d4d4d4d4d4d4d4", Message: "docs: Update the readme"},
+	{SHA: "e5e5e5e5e5e5e5e5", Message: "Merge branch 'feature'"},
+	{SHA: "f6f6f6f6f6f6f6f6", Message: "perf(db): Cache the queries"},
+	{SHA: "a7a7a7a7a7a7a7a7", Message: "fix(api): Reject malformed cursors"},
+	{SHA: "b8b8b8b8b8b8b8b8", Message: "feat(auth)!: Require tokens\n\nBREAKING CHANGE: the tokens\nare required"},
+	{SHA: "c9c9c9c9c9c9c9c9", Message: "refactor!: Drop the v1 endpoints"},
+	{SHA: "d0d0d0d0d0d0d0d0", Message: "revert: Undo the cache warmup"},
+	{SHA: "e1e1", Message: "security: Escape the search terms"},
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		release Release
+		cfg     *config.Config
+		fixture string
+	}{
+		{
+			name:    "default commit types",
+			release: Release{Title: "1.2.0", Date: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Commits: history},
+			cfg:     config.NewDefault(),
+			fixture: "release.md",
+		},
+		{
+			name:    "configured titles, order and hidden types",
+			release: Release{Title: "Unreleased", Commits: history},
+			cfg: &config.Config{CommitTypes: []config.CommitType{
+				{Name: "security", ChangelogTitle: "Security"},
+				{Name: "fix", ChangelogTitle: "Fixes"},
+				{Name: "feat"},
+				{Name: "perf", Hidden: true},
+			}},
+			fixture: "configured_types.md",
+		},
+		{
+			name:    "empty release",
+			release: Release{Title: "1.2.1", Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Commits: history[3:5]},
+			cfg:     config.NewDefault(),
+			fixture: "empty.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Generate(tt.release, tt.cfg)
+			if want := readFixture(t, tt.fixture); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	section := readFixture(t, "release.md")
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{name: "new file", existing: "", want: Header + "\n" + section},
+		{name: "empty file", existing: "\n\n", want: Header + "\n" + section},
+		{name: "header and releases", existing: readFixture(t, "existing.md"), want: readFixture(t, "existing_prepended.md")},
+		{
+			name:     "releases without a header",
+			existing: readFixture(t, "existing_without_header.md"),
+			want:     readFixture(t, "existing_without_header_prepended.md"),
+		},
+		{name: "header without releases", existing: Header, want: Header + "\n" + section},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Prepend(tt.existing, section); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
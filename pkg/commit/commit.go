@@ -9,108 +9,125 @@ package commit
 
 import (
 	"commitsense/pkg/config"
+	"commitsense/pkg/git"
 	"errors"
-	"os"
-	"os/exec"
 	"strings"
 )
 
-// Commit represents information needed for creating a Git commit.
+// Commit represents information needed for creating a Git commit. The json tags define the serialized
+// form of a commit, e.g. {"type": "feat", "scope": "api", "description": "Add an endpoint"}.
 type Commit struct {
-	CommitType                string
-	CommitScope               string
-	CommitDescription         string
-	CommitBody                string
-	IsCoAuthored              bool
-	CoAuthors                 []string
-	IsBreakingChange          bool
-	BreakingChangeDescription string
-	StagedFiles               []string
+	CommitType        string `json:"type"`
+	CommitScope       string `json:"scope,omitempty"`
+	CommitDescription string `json:"description"`
+	CommitBody        string `json:"body,omitempty"`
+	// IsCoAuthored is derived from CoAuthors in the serialized form.
+	IsCoAuthored              bool         `json:"-"`
+	CoAuthors                 []string     `json:"co_authors,omitempty"`
+	IsBreakingChange          bool         `json:"breaking,omitempty"`
+	BreakingChangeDescription string       `json:"breaking_description,omitempty"`
+	Footers                   []Footer     `json:"trailers,omitempty"`
+	StagedFiles               []StagedFile `json:"staged_files,omitempty"`
+	// Only are the paths of the files to commit with their current content instead of the index,
+	// as with `git commit --only`.
+	Only []string `json:"only,omitempty"`
 }
 
-// CreateGitCommit creates a Git commit from the commit struct.
-func (c *Commit) CreateGitCommit() error {
-	commitMessage := createCommitMessage(c)
+// StagedFile represents a file with changes staged for the commit.
+type StagedFile = git.StagedFile
 
-	commitArgs := append([]string{"commit", "-m", commitMessage}, c.StagedFiles...)
+// UnmergedError is returned for the staged files while files have unresolved conflicts.
+type UnmergedError = git.UnmergedError
 
-	commitGitCmd := exec.Command("git", commitArgs...) //nolint:gosec // because I do not think the users can do anything bad here
-	commitGitCmd.Stdout = os.Stdout
-	commitGitCmd.Stderr = os.Stderr
+// Footer represents a single git trailer style footer of a commit message,
+// such as "Refs: #123" or "Reviewed-by: Jane Doe <jane@example.com>".
+type Footer struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
 
-	if err := commitGitCmd.Run(); err != nil {
-		return err
+// String returns the footer formatted as a git trailer.
+func (f Footer) String() string {
+	if strings.HasPrefix(f.Value, "#") {
+		return f.Token + " " + f.Value
 	}
+	return f.Token + ": " + f.Value
+}
 
-	updateIndexCmd := exec.Command("git", "update-index", "-g")
-	updateIndexCmd.Stdout = os.Stdout
-	updateIndexCmd.Stderr = os.Stderr
+// GitCommand returns the git command line CreateGitCommit runs to create the commit.
+func (c *Commit) GitCommand() []string {
+	return git.CommitCommand(createCommitMessage(c), c.Only)
+}
 
-	return updateIndexCmd.Run()
+// CreateGitCommit creates a Git commit from the commit struct. Exactly the changes staged in the index are
+// committed, or only the files of Only when it is set.
+func (c *Commit) CreateGitCommit() error {
+	return git.Current().Commit(createCommitMessage(c), c.Only)
 }
 
-// GetStagedFiles returns a list of staged files.
-func GetStagedFiles() ([]string, error) {
-	statusCmd := "git status --porcelain --untracked-files=all | grep '^[A|C|M|D|R]'"
-	cmd := exec.Command("bash", "-c", statusCmd)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, errors.New("could not get staged files, is the files added for staging?")
+// Paths returns the paths of the files the commit changes: the paths of Only when it is set, and the paths
+// of the staged files otherwise.
+func (c *Commit) Paths() []string {
+	if len(c.Only) > 0 {
+		return c.Only
 	}
+	return git.Paths(c.StagedFiles)
+}
 
-	stagedFiles := getStagedFilesFromTerminalOutput(output)
-
-	return stagedFiles, nil
+// Message returns the commit message in the Conventional Commits format.
+func (c *Commit) Message() string {
+	return createCommitMessage(c)
 }
 
-// getStringsFromTerminalOutput takes the os/exec functions returned byte array
-// and transforms the bytes into an array of lines
-func getStagedFilesFromTerminalOutput(output []byte) []string {
-	lines := strings.Split(string(output), "\n")
+// Header returns the first line of the commit message, e.g. "feat(api)!: add an endpoint".
+func (c *Commit) Header() string {
+	header := c.CommitType
 
-	var stagedFiles []string
-	for _, line := range lines {
-		// Strip leading and trailing whitespace
-		line = strings.TrimSpace(line)
+	if c.CommitScope != "" {
+		header += "(" + c.CommitScope + ")"
+	}
 
-		parts := strings.Fields(line)
-		if len(parts) == 2 {
-			stagedFiles = append(stagedFiles, parts[1])
-		}
+	if c.IsBreakingChange {
+		header += "!"
 	}
 
-	return stagedFiles
+	return header + ": " + c.CommitDescription
 }
 
-// CreateCommitMessage creates a commit message in the Conventional Commits format.
-func createCommitMessage(commit *Commit) string {
-	var commitMessage string
-
-	commitMessage = commit.CommitType
-	cfg, _ := config.Read()
-
-	if commit.CommitScope != "" {
-		commitMessage += "(" + commit.CommitScope + ")"
+// GetStagedFiles returns the files with changes staged for the commit. While files have unresolved
+// conflicts an *UnmergedError is returned instead.
+func GetStagedFiles() ([]StagedFile, error) {
+	stagedFiles, err := git.Current().StagedFiles()
+	var unmerged *UnmergedError
+	if errors.As(err, &unmerged) {
+		return nil, err
 	}
-
-	if commit.IsBreakingChange {
-		commitMessage += "!"
+	if err != nil || len(stagedFiles) == 0 {
+		return nil, errors.New("could not get staged files, is the files added for staging?")
 	}
 
-	commitMessage += ": " + commit.CommitDescription
+	return stagedFiles, nil
+}
+
+// CreateCommitMessage creates a commit message in the Conventional Commits format.
+func createCommitMessage(commit *Commit) string {
+	commitMessage := commit.Header()
+	cfg, _ := config.Read()
 
 	if commit.CommitBody != "" {
 		commitMessage += "\n\n" + commit.CommitBody
 	}
 
-	for _, skipType := range cfg.SkipCITypes {
-		if commit.CommitType == skipType {
-			commitMessage += "\n[skip ci]"
-			break
+	if cfg != nil {
+		for _, skipType := range cfg.SkipCITypes {
+			if commit.CommitType == skipType {
+				commitMessage += "\n" + SkipCIMarker
+				break
+			}
 		}
 	}
 
-	if commit.IsBreakingChange {
+	if commit.IsBreakingChange && commit.BreakingChangeDescription != "" {
 		commitMessage += "\n"
 		commitMessage += "\nBREAKING CHANGE: " + commit.BreakingChangeDescription
 	}
@@ -122,5 +139,12 @@ func createCommitMessage(commit *Commit) string {
 		}
 	}
 
+	if len(commit.Footers) > 0 {
+		commitMessage += "\n"
+		for _, footer := range commit.Footers {
+			commitMessage += "\n" + footer.String()
+		}
+	}
+
 	return commitMessage
 }
@@ -0,0 +1,72 @@
+/*
+Package commit provides functionality for creating Git commits.
+
+This file includes utility functions for reading existing commits from the Git history.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package commit
+
+import (
+	"commitsense/pkg/git"
+	"strings"
+)
+
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// LogEntry represents a single commit read from the Git history.
+type LogEntry = git.LogEntry
+
+// GetCommitLog returns the commits in the given revision range, e.g. "origin/main..HEAD", newest first.
+// Merge commits are left out as their messages are generated by Git.
+func GetCommitLog(revisionRange string) ([]LogEntry, error) {
+	return git.Current().Log(revisionRange)
+}
+
+// GetLatestTag returns the most recent tag reachable from HEAD, or an empty string if there are no tags.
+func GetLatestTag() (string, error) {
+	tags, err := git.Current().Tags()
+	if err != nil || len(tags) == 0 {
+		return "", err
+	}
+
+	return tags[0], nil
+}
+
+// GetTags returns the tags reachable from HEAD, the most recently created first.
+func GetTags() ([]string, error) {
+	return git.Current().Tags()
+}
+
+// CreateTag creates an annotated tag pointing to HEAD.
+func CreateTag(name string, message string) error {
+	return git.Current().CreateTag(name, message)
+}
+
+// StripComments removes the comment lines Git adds to commit message templates and cleans up the
+// whitespace, as done by `git commit --cleanup=strip`: trailing whitespace is removed from the lines,
+// consecutive blank lines are collapsed into one and the leading and trailing blank lines are removed.
+// Everything below the scissors line added by `git commit --verbose` is removed as well.
+func StripComments(message string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, scissorsLine) {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimRight(line, " \t")
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
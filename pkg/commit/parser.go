@@ -0,0 +1,265 @@
+/*
+Package commit provides functionality for creating Git commits.
+
+This file includes functions for parsing existing commit messages written in the Conventional Commits format.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package commit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SkipCIMarker is the line added to the messages of the commit types that skip the CI run.
+const SkipCIMarker = "[skip ci]"
+
+// footerPattern matches a git trailer style footer line, e.g. "Refs: #123", "Closes #42" or "BREAKING CHANGE: ...".
+var footerPattern = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Za-z0-9][A-Za-z0-9-]*)(:[ \t]|[ \t]#|:$)(.*)$`)
+
+// tokenPattern matches the token of a git trailer.
+var tokenPattern = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Za-z0-9][A-Za-z0-9-]*)$`)
+
+// ParseError describes a commit message that does not follow the Conventional Commits format.
+// Line and Column are 1-based and point to the position where parsing failed.
+type ParseError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// ParseCommitMessage parses a commit message in the Conventional Commits format into a Commit.
+//
+// The header is parsed into the type, optional scope, breaking change marker and description. Everything after
+// the header is split into paragraphs; the trailing paragraphs that start with a git trailer are treated as footers
+// and the rest as the body. BREAKING CHANGE and BREAKING-CHANGE footers mark the commit as breaking and
+// Co-authored-by footers are collected as co-authors. The "[skip ci]" marker added by CommitSense is dropped
+// from the body as it is derived from the configuration when the message is created.
+func ParseCommitMessage(message string) (*Commit, error) {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+
+	if strings.TrimSpace(strings.Join(lines, "")) == "" {
+		return nil, &ParseError{Line: 1, Column: 1, Message: "commit message is empty"}
+	}
+
+	c := &Commit{}
+	if err := parseHeader(lines[0], c); err != nil {
+		return nil, err
+	}
+
+	paragraphs := splitParagraphs(lines[1:])
+
+	footerStart := len(paragraphs)
+	for footerStart > 0 && footerPattern.MatchString(paragraphs[footerStart-1][0]) {
+		footerStart--
+	}
+
+	var body []string
+	for i, paragraph := range paragraphs[:footerStart] {
+		if i > 0 {
+			body = append(body, "")
+		}
+		body = append(body, paragraph...)
+	}
+	c.CommitBody = stripSkipCIMarker(body)
+
+	for _, paragraph := range paragraphs[footerStart:] {
+		parseFooters(paragraph, c)
+	}
+
+	return c, nil
+}
+
+// parseHeader parses the "<type>(<scope>)!: <description>" header line into the commit.
+func parseHeader(header string, c *Commit) error {
+	runes := []rune(header)
+	pos := 0
+
+	headerError := func(format string, args ...interface{}) error {
+		return &ParseError{Line: 1, Column: pos + 1, Message: fmt.Sprintf(format, args...)}
+	}
+
+	for pos < len(runes) && isTypeRune(runes[pos]) {
+		pos++
+	}
+	if pos == 0 {
+		return headerError("expected a commit type")
+	}
+	c.CommitType = string(runes[:pos])
+
+	if pos < len(runes) && runes[pos] == '(' {
+		scope, end, err := parseScope(runes, pos)
+		if err != nil {
+			return err
+		}
+		c.CommitScope = scope
+		pos = end
+	}
+
+	if pos < len(runes) && runes[pos] == '!' {
+		c.IsBreakingChange = true
+		pos++
+	}
+
+	if pos >= len(runes) || runes[pos] != ':' {
+		return headerError("expected ':' after the commit type")
+	}
+	pos++
+
+	if pos < len(runes) && runes[pos] != ' ' {
+		return headerError("expected a space after ':'")
+	}
+	if pos < len(runes) {
+		pos++
+	}
+
+	description := strings.TrimSpace(string(runes[pos:]))
+	if description == "" {
+		return headerError("commit description must not be empty")
+	}
+	c.CommitDescription = description
+
+	return nil
+}
+
+// parseScope parses the "(<scope>)" of the header starting at the '(' at pos. It returns the scope and the
+// position after the closing ')'.
+func parseScope(runes []rune, pos int) (string, int, error) {
+	scopeError := func(column int, message string) error {
+		return &ParseError{Line: 1, Column: column + 1, Message: message}
+	}
+
+	start := pos + 1
+	end := start
+	for end < len(runes) && runes[end] != ')' {
+		if runes[end] == '(' {
+			return "", 0, scopeError(end, "unexpected '(' inside the commit scope")
+		}
+		end++
+	}
+	if end == len(runes) {
+		return "", 0, scopeError(end, "expected ')' to close the commit scope")
+	}
+	if strings.TrimSpace(string(runes[start:end])) == "" {
+		return "", 0, scopeError(start, "commit scope must not be empty")
+	}
+
+	return string(runes[start:end]), end + 1, nil
+}
+
+func isTypeRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_'
+}
+
+// splitParagraphs groups lines into paragraphs separated by one or more blank lines.
+func splitParagraphs(lines []string) [][]string {
+	var paragraphs [][]string
+	var current []string
+
+	for _, line := range lines {
+		if line == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+
+	return paragraphs
+}
+
+// stripSkipCIMarker removes the standalone skip ci marker lines from the body and joins it back together.
+func stripSkipCIMarker(body []string) string {
+	var lines []string
+	for _, line := range body {
+		if strings.TrimSpace(line) == SkipCIMarker {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// parseFooters parses a paragraph of git trailers into the commit. Lines that do not start a new
+// trailer are treated as a continuation of the previous trailer's value.
+func parseFooters(paragraph []string, c *Commit) {
+	var footers []Footer
+
+	for _, line := range paragraph {
+		match := footerPattern.FindStringSubmatch(line)
+		if match == nil {
+			if len(footers) > 0 {
+				last := &footers[len(footers)-1]
+				last.Value = strings.TrimSpace(last.Value + "\n" + strings.TrimSpace(line))
+			}
+			continue
+		}
+
+		value := strings.TrimSpace(match[3])
+		if strings.TrimSpace(match[2]) == "#" {
+			value = "#" + value
+		}
+		footers = append(footers, Footer{Token: match[1], Value: value})
+	}
+
+	for _, footer := range footers {
+		switch {
+		case isBreakingChangeToken(footer.Token):
+			c.IsBreakingChange = true
+			c.BreakingChangeDescription = footer.Value
+		case strings.EqualFold(footer.Token, "Co-authored-by"):
+			c.IsCoAuthored = true
+			c.CoAuthors = append(c.CoAuthors, footer.Value)
+		default:
+			c.Footers = append(c.Footers, footer)
+		}
+	}
+}
+
+// ParseFooter parses a single git trailer, e.g. "Refs: #123", "Closes #42" or "Reviewed-by=Jane Doe",
+// the last being the token=value form accepted by git interpret-trailers.
+func ParseFooter(s string) (Footer, error) {
+	s = strings.TrimSpace(s)
+
+	if token, value, found := strings.Cut(s, "="); found && tokenPattern.MatchString(token) {
+		s = token + ": " + strings.TrimSpace(value)
+	}
+
+	match := footerPattern.FindStringSubmatch(s)
+	if match == nil || strings.TrimSpace(match[3]) == "" {
+		return Footer{}, fmt.Errorf("invalid trailer %q, expected the format \"Token: value\"", s)
+	}
+
+	value := strings.TrimSpace(match[3])
+	if strings.TrimSpace(match[2]) == "#" {
+		value = "#" + value
+	}
+
+	return Footer{Token: match[1], Value: value}, nil
+}
+
+// IsFooter reports whether the line starts a git trailer, e.g. "Refs: #123" or "BREAKING CHANGE: ...".
+func IsFooter(line string) bool {
+	return footerPattern.MatchString(line)
+}
+
+func isBreakingChangeToken(token string) bool {
+	return token == "BREAKING CHANGE" || token == "BREAKING-CHANGE"
+}
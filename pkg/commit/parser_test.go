@@ -0,0 +1,138 @@
+package commit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		commitType  string
+		scope       string
+		breaking    bool
+		description string
+	}{
+		{name: "type only", header: "feat: Add an endpoint", commitType: "feat", description: "Add an endpoint"},
+		{name: "scope", header: "fix(api): Handle empty pages", commitType: "fix", scope: "api", description: "Handle empty pages"},
+		{name: "breaking", header: "feat!: Drop the v1 API", commitType: "feat", breaking: true, description: "Drop the v1 API"},
+		{name: "scope and breaking", header: "refactor(core)!: Rename the config", commitType: "refactor", scope: "core", breaking: true, description: "Rename the config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCommitMessage(tt.header)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if c.CommitType != tt.commitType || c.CommitScope != tt.scope || c.CommitDescription != tt.description {
+				t.Errorf("got %q, %q, %q, want %q, %q, %q",
+					c.CommitType, c.CommitScope, c.CommitDescription, tt.commitType, tt.scope, tt.description)
+			}
+			if c.IsBreakingChange != tt.breaking {
+				t.Errorf("got IsBreakingChange %v, want %v", c.IsBreakingChange, tt.breaking)
+			}
+		})
+	}
+}
+
+func TestParseHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		column int
+	}{
+		{name: "empty message", header: "", column: 1},
+		{name: "no type", header: ": Add an endpoint", column: 1},
+		{name: "no colon", header: "feat Add an endpoint", column: 5},
+		{name: "nothing after colon", header: "feat:", column: 6},
+		{name: "nothing after scope", header: "feat(x):", column: 9},
+		{name: "nothing after breaking", header: "feat!:", column: 7},
+		{name: "only a space after colon", header: "feat: ", column: 6},
+		{name: "no space after colon", header: "docs:Fix a typo", column: 6},
+		{name: "empty scope", header: "feat(): Add", column: 6},
+		{name: "unclosed scope", header: "feat(api: Add", column: 14},
+		{name: "nested scope", header: "feat(a(b)): Add", column: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCommitMessage(tt.header)
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("got error %v, want a *ParseError", err)
+			}
+			if parseErr.Line != 1 || parseErr.Column != tt.column {
+				t.Errorf("got error at %d:%d, want 1:%d: %v", parseErr.Line, parseErr.Column, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestParseBodyAndFooters(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		body      string
+		breaking  string
+		coAuthors []string
+		footers   []Footer
+	}{
+		{
+			name:    "multi-paragraph body",
+			message: "feat: Add pages\n\nFirst paragraph\nstill first.\n\n\nSecond paragraph.",
+			body:    "First paragraph\nstill first.\n\nSecond paragraph.",
+		},
+		{
+			name:    "body and footers",
+			message: "fix: Handle errors\n\nThe body.\n\nRefs: #123\nCloses #42",
+			body:    "The body.",
+			footers: []Footer{{Token: "Refs", Value: "#123"}, {Token: "Closes", Value: "#42"}},
+		},
+		{
+			name:     "breaking change footer",
+			message:  "feat: Drop v1\n\nBREAKING CHANGE: the v1 endpoints\nare gone",
+			breaking: "the v1 endpoints\nare gone",
+		},
+		{
+			name:      "co-authors",
+			message:   "feat: Pair on it\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: John Doe <john@example.com>",
+			coAuthors: []string{"Jane Doe <jane@example.com>", "John Doe <john@example.com>"},
+		},
+		{
+			name:    "skip ci marker",
+			message: "docs: Update the readme\n\nThe body.\n[skip ci]",
+			body:    "The body.",
+		},
+		{
+			name:    "footers after a body paragraph starting like a trailer",
+			message: "feat: Add pages\n\nNote: the body.\nMore of the body.\n\nThe end of the body.\n\nRefs: #1",
+			body:    "Note: the body.\nMore of the body.\n\nThe end of the body.",
+			footers: []Footer{{Token: "Refs", Value: "#1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCommitMessage(tt.message)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if c.CommitBody != tt.body {
+				t.Errorf("got body %q, want %q", c.CommitBody, tt.body)
+			}
+			if c.BreakingChangeDescription != tt.breaking || c.IsBreakingChange != (tt.breaking != "") {
+				t.Errorf("got breaking change %v %q, want %q", c.IsBreakingChange, c.BreakingChangeDescription, tt.breaking)
+			}
+			if !reflect.DeepEqual(c.CoAuthors, tt.coAuthors) {
+				t.Errorf("got co-authors %q, want %q", c.CoAuthors, tt.coAuthors)
+			}
+			if !reflect.DeepEqual(c.Footers, tt.footers) {
+				t.Errorf("got footers %v, want %v", c.Footers, tt.footers)
+			}
+		})
+	}
+}
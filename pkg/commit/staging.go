@@ -0,0 +1,130 @@
+/*
+Package commit provides functionality for creating Git commits.
+
+This file includes utility functions for listing the changed files of the working tree and staging them.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package commit
+
+import (
+	"commitsense/pkg/git"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const previewMaxLines = 20
+
+// ChangedFile represents a file with changes in the working tree or in the index.
+type ChangedFile struct {
+	Path string
+	// Status is a human readable status of the file, e.g. "modified", "untracked" or "deleted".
+	Status   string
+	IsStaged bool
+}
+
+// GetChangedFiles returns the modified, untracked and deleted files of the working tree,
+// marking the files that already have changes staged.
+func GetChangedFiles() ([]ChangedFile, error) {
+	status, err := git.Current().Status()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]ChangedFile, 0, len(status))
+	for _, file := range status {
+		files = append(files, ChangedFile{
+			Path:     file.Path,
+			Status:   describeStatus(file),
+			IsStaged: file.IsStaged(),
+		})
+	}
+
+	return files, nil
+}
+
+func describeStatus(file git.FileStatus) string {
+	index, worktree := file.Index, file.Worktree
+
+	switch {
+	case file.IsUnmerged():
+		return "unmerged"
+	case index == '?':
+		return "untracked"
+	case index == 'D' || worktree == 'D':
+		return "deleted"
+	case index == 'A':
+		return "added"
+	case index == 'R':
+		return "renamed"
+	case index == 'C':
+		return "copied"
+	case index == 'T' || worktree == 'T':
+		return "type changed"
+	default:
+		return "modified"
+	}
+}
+
+// GetFileDiffPreview returns the first lines of the changes made to the file, staged or not.
+// For untracked files the beginning of the file is returned.
+func GetFileDiffPreview(file ChangedFile) string {
+	var preview string
+
+	if file.Status == "untracked" {
+		root, err := git.Current().Root()
+		if err != nil {
+			return err.Error()
+		}
+		content, err := os.ReadFile(filepath.Join(root, file.Path))
+		if err != nil {
+			return err.Error()
+		}
+		preview = string(content)
+	} else {
+		repo := git.Current()
+		diff, err := repo.Diff(false, file.Path)
+		if err == nil && diff == "" {
+			diff, err = repo.Diff(true, file.Path)
+		}
+		if err != nil {
+			return err.Error()
+		}
+		preview = diff
+	}
+
+	lines := strings.Split(strings.TrimRight(preview, "\n"), "\n")
+	if len(lines) > previewMaxLines {
+		lines = append(lines[:previewMaxLines], fmt.Sprintf("... %d more lines", len(lines)-previewMaxLines))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// StageFiles adds the current content of the files, including deletions, to the index.
+func StageFiles(paths []string) error {
+	return git.Current().Stage(paths)
+}
+
+// UnstageFiles removes the staged changes of the files from the index, leaving the working tree untouched.
+func UnstageFiles(paths []string) error {
+	return git.Current().Unstage(paths)
+}
+
+// GetUnstagedDiff returns the changes of the working tree that are not staged, as a unified diff
+// with paths relative to the repository root.
+func GetUnstagedDiff() (string, error) {
+	diff, err := git.Current().Diff(false, "")
+	if err != nil {
+		return "", fmt.Errorf("could not get the unstaged changes: %w", err)
+	}
+	return diff, nil
+}
+
+// ApplyPatchToIndex applies the patch, with paths relative to the repository root, to the index
+// without touching the working tree.
+func ApplyPatchToIndex(patch string) error {
+	return git.Current().ApplyToIndex(patch)
+}
@@ -0,0 +1,60 @@
+/*
+Package commit provides functionality for creating Git commits.
+
+This file includes functions for wrapping the body of commit messages.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package commit
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// listItemPattern matches the marker of a list item, e.g. "- ", "* " or "1. ".
+var listItemPattern = regexp.MustCompile(`^([-*+]|\d+[.)])\s+`)
+
+// WrapBody wraps the lines of a commit body that are longer than the width at word boundaries. Indented
+// lines, such as code blocks, are left as they are, and the continuation lines of list items are indented
+// under the text of the item. Words longer than the width, such as URLs, are kept whole.
+func WrapBody(body string, width int) string {
+	if width <= 0 {
+		return body
+	}
+
+	var wrapped []string
+	for _, line := range strings.Split(body, "\n") {
+		if utf8.RuneCountInString(line) <= width || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			wrapped = append(wrapped, line)
+			continue
+		}
+
+		indent := strings.Repeat(" ", utf8.RuneCountInString(listItemPattern.FindString(line)))
+		wrapped = append(wrapped, wrapLine(line, width, indent)...)
+	}
+
+	return strings.Join(wrapped, "\n")
+}
+
+// wrapLine breaks the line into lines of at most width characters, starting the continuation lines with
+// the indent.
+func wrapLine(line string, width int, indent string) []string {
+	var lines []string
+
+	current := ""
+	for _, word := range strings.Fields(line) {
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = indent + word
+		}
+	}
+
+	return append(lines, current)
+}
@@ -8,97 +8,130 @@ Copyright © 2023 HENRI REMONEN <henri@remonen.fi>
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
-
-	colorprinter "commitsense/internal/printer"
+	"path/filepath"
 
 	"github.com/spf13/viper"
 )
 
 var (
-	configFile         *Config
 	configFileName     = "commitsense.config.json"
-	defaultVersion     = 1
-	defaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+	defaultCommitTypes = []CommitType{
+		{Name: "feat"}, {Name: "fix"}, {Name: "docs"}, {Name: "style"}, {Name: "refactor"}, {Name: "perf"},
+		{Name: "test"}, {Name: "build"}, {Name: "ci"}, {Name: "chore"}, {Name: "revert"},
+	}
 	defaultSkipCITypes = []string{"docs"}
+
+	// defaultChangelogSections are the changelog section titles of the standard commit types.
+	defaultChangelogSections = map[string]string{
+		"feat":   "Features",
+		"fix":    "Bug Fixes",
+		"perf":   "Performance Improvements",
+		"revert": "Reverts",
+	}
+	// defaultBumpTypes are the version bumps caused by the standard commit types.
+	defaultBumpTypes = map[string]string{
+		"feat": "minor",
+		"fix":  "patch",
+		"perf": "patch",
+	}
 )
 
+// ErrNoCommitTypes is returned by Read when the configuration has no commit types.
+var ErrNoCommitTypes = errors.New("no commit types are configured, add commit types to commit_types in the configuration")
+
 // Config represents the configuration settings for the application.
 type Config struct {
-	Version     int      `json:"version"`
-	CommitTypes []string `json:"commit_types"`
-	SkipCITypes []string `json:"skip_ci_types"`
+	Version     int          `json:"version"`
+	CommitTypes []CommitType `json:"commit_types" jsonschema:"minItems=1"`
+	SkipCITypes []string     `json:"skip_ci_types"`
+	Scopes      []Scope      `json:"scopes,omitempty"`
+	// EnforceScopes only allows the scopes listed in Scopes to be used.
+	EnforceScopes bool `json:"enforce_scopes,omitempty"`
+	// ScopeModes maps commit types to whether a scope is required, optional or forbidden for them.
+	ScopeModes map[string]string `json:"scope_modes,omitempty" jsonschema:"enum=required,enum=optional,enum=forbidden"`
+	// BodyEditor composes the commit body in the editor of the user instead of the body prompt.
+	BodyEditor bool `json:"body_editor,omitempty"`
+	// Rules configures the levels and options of the lint rules by rule name.
+	Rules map[string]RuleConfig `json:"rules,omitempty"`
 }
 
 // NewDefault creates a new default configuration object.
 func NewDefault() *Config {
 	return &Config{
-		Version:     defaultVersion,
+		Version:     CurrentVersion,
 		CommitTypes: defaultCommitTypes,
 		SkipCITypes: defaultSkipCITypes,
 	}
 }
 
-// On CommitSense start up, check if the configuration file exists.
-// If it does not exist, create a default configuration file.
-func init() {
-	if !exists() {
-		cfg := NewDefault()
+// Read resolves the configuration from its layers, see Load. Configuration files of older versions
+// are migrated to the current version in memory.
+func Read() (*Config, error) {
+	raw, _, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return Decode(raw)
+}
 
-		err := Write(cfg)
-		if err != nil {
-			colorprinter.ColorPrint("error", "Error creating default config: %v", err)
-			os.Exit(1)
-		}
+// Decode decodes a raw configuration, as returned by Load, into a Config.
+func Decode(raw map[string]interface{}) (*Config, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
 
-		colorprinter.ColorPrint("info", "\nCould not find an existing configuration file")
-		colorprinter.ColorPrint("success", "Created default configuration file at %v", configFileName)
+	viper.SetConfigType("json")
+	if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, err
 	}
 
-	config, err := Read()
+	commitTypes, err := decodeCommitTypes(viper.Get("commit_types"))
 	if err != nil {
-		os.Exit(1)
+		return nil, err
 	}
 
-	configFile = config
-}
+	if len(commitTypes) == 0 {
+		return nil, ErrNoCommitTypes
+	}
 
-// Exists checks if the configuration file exists in the project's root directory.
-func exists() bool {
-	if fi, err := os.Stat(configFileName); err != nil || fi.IsDir() {
-		return false
+	var scopes []Scope
+	if err := viper.UnmarshalKey("scopes", &scopes); err != nil {
+		return nil, fmt.Errorf("invalid scopes: %w", err)
 	}
-	return true
-}
 
-// Read reads the configuration file from the project's root directory.
-func Read() (*Config, error) {
-	viper.SetConfigFile(configFileName)
-
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			colorprinter.ColorPrint("error", "Error config file not found: %v", err)
-			return nil, err
-		}
-		// Config file was found but another error was produced
-		colorprinter.ColorPrint("error", "Error reading config file: %v", err)
+	rules, err := decodeRules(viper.Get("rules"))
+	if err != nil {
 		return nil, err
 	}
 
 	return &Config{
-		Version:     viper.GetInt("version"),
-		CommitTypes: viper.GetStringSlice("commit_types"),
-		SkipCITypes: viper.GetStringSlice("skip_ci_types"),
+		Version:       viper.GetInt("version"),
+		CommitTypes:   commitTypes,
+		SkipCITypes:   viper.GetStringSlice("skip_ci_types"),
+		Scopes:        scopes,
+		EnforceScopes: viper.GetBool("enforce_scopes"),
+		ScopeModes:    viper.GetStringMapString("scope_modes"),
+		BodyEditor:    viper.GetBool("body_editor"),
+		Rules:         rules,
 	}, nil
 }
 
-// Write writes the configuration file to the project's root directory.
-func Write(config *Config) error {
-	viper.SetConfigFile(configFileName)
+// Write writes the configuration to commitsense.config.json in the root of the repository,
+// replacing an existing file, and returns the path of the file.
+func Write(config *Config) (string, error) {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
 
-	viper.Set("version", config.Version)
-	viper.Set("commit_types", config.CommitTypes)
-	viper.Set("skip_ci_types", config.SkipCITypes)
+	path := filepath.Join(RepositoryDir(), configFileName)
 
-	return viper.WriteConfig()
+	return path, os.WriteFile(path, append(data, '\n'), 0o644) //nolint:gosec // the configuration is meant to be shared
 }
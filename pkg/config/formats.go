@@ -0,0 +1,247 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes utility functions for reading and writing configuration files in the JSON, YAML and TOML
+formats, the commitsense key of package.json and the rules of commitlint configuration files.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	packageJSONFileName = "package.json"
+	packageJSONKey      = "commitsense"
+)
+
+// repositoryFileNames are the names of the configuration files in the root of a repository,
+// in the order of precedence. The first of the files that exists is used.
+var repositoryFileNames = []string{
+	configFileName,
+	".commitsense.yaml",
+	".commitsense.yml",
+	".commitsense.toml",
+	packageJSONFileName,
+}
+
+// globalFileNames are the names of the global configuration file in the order of precedence.
+var globalFileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// commitlintFileNames are the names of the commitlint configuration files the rules are imported from.
+var commitlintFileNames = []string{".commitlintrc.json", ".commitlintrc.yaml", ".commitlintrc.yml", ".commitlintrc"}
+
+// findFile returns the path of the first of the configuration files that exists in the directory,
+// or an empty string if there is none. A package.json file only counts if it has a commitsense key.
+func findFile(dir string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if !exists(path) {
+			continue
+		}
+
+		if name == packageJSONFileName {
+			if _, err := readPackageJSON(path); err != nil {
+				continue
+			}
+		}
+
+		return path
+	}
+
+	return ""
+}
+
+func exists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// readRaw reads a configuration file without interpreting it. The format of the file is chosen by
+// its extension, and only the commitsense key of a package.json file is read.
+func readRaw(path string) (map[string]interface{}, error) {
+	if filepath.Base(path) == packageJSONFileName {
+		return readPackageJSON(path)
+	}
+
+	if filepath.Ext(path) != ".json" {
+		return readSettings(path, "")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON: %w", path, err)
+	}
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+
+	return raw, nil
+}
+
+// readSettings reads a configuration file in any of the formats supported by viper. The values are
+// normalized to the types produced by encoding/json, e.g. float64 for all numbers.
+func readSettings(path string, format string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if format != "" {
+		v.SetConfigType(format)
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, fs.ErrNotExist
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	data, err := json.Marshal(v.AllSettings())
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return raw, nil
+}
+
+// readPackageJSON reads the commitsense key of a package.json file. A file without the key is
+// reported as fs.ErrNotExist.
+func readPackageJSON(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON: %w", path, err)
+	}
+
+	value, ok := pkg[packageJSONKey]
+	if !ok {
+		return nil, fmt.Errorf("%s has no %s key: %w", path, packageJSONKey, fs.ErrNotExist)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, fmt.Errorf("the %s key of %s must be an object: %w", packageJSONKey, path, err)
+	}
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+
+	return raw, nil
+}
+
+// writeRaw writes a raw configuration to a file in the format chosen by its extension.
+func writeRaw(path string, raw map[string]interface{}) error {
+	if filepath.Base(path) == packageJSONFileName {
+		return fmt.Errorf("%s is not written by CommitSense, update its %s key by hand", path, packageJSONKey)
+	}
+
+	if filepath.Ext(path) != ".json" {
+		v := viper.New()
+		for key, value := range raw {
+			v.Set(key, value)
+		}
+		return v.WriteConfigAs(path)
+	}
+
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), info.Mode().Perm())
+}
+
+// readCommitlintFile imports the type-enum and scope-enum rules of a commitlint configuration file
+// as a raw configuration. Scopes of a scope-enum rule with the error level are enforced.
+func readCommitlintFile(path string) (map[string]interface{}, error) {
+	// The .commitlintrc file is either JSON or YAML, and YAML is a superset of JSON.
+	format := ""
+	if filepath.Ext(path) == "" || filepath.Base(path) == ".commitlintrc" {
+		format = "yaml"
+	}
+
+	settings, err := readSettings(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, _ := settings["rules"].(map[string]interface{})
+	raw := map[string]interface{}{"version": CurrentVersion}
+
+	if types, _, ok := enumRule(rules["type-enum"]); ok {
+		raw["commit_types"] = types
+	}
+
+	if scopes, level, ok := enumRule(rules["scope-enum"]); ok {
+		objects := make([]interface{}, 0, len(scopes))
+		for _, scope := range scopes {
+			objects = append(objects, map[string]interface{}{"name": scope})
+		}
+		raw["scopes"] = objects
+		raw["enforce_scopes"] = level == commitlintLevelError
+	}
+
+	return raw, nil
+}
+
+// commitlintLevelError is the level of commitlint rules reported as errors.
+const commitlintLevelError = 2
+
+// enumRule returns the values and the level of an enabled commitlint enum rule,
+// e.g. [2, "always", ["feat", "fix"]], and whether the rule could be imported.
+func enumRule(rule interface{}) ([]interface{}, int, bool) {
+	parts, ok := rule.([]interface{})
+	if !ok || len(parts) != 3 {
+		return nil, 0, false
+	}
+
+	level, ok := parts[0].(float64)
+	if !ok || level <= 0 {
+		return nil, 0, false
+	}
+
+	if applicable, _ := parts[1].(string); !strings.EqualFold(applicable, "always") {
+		return nil, 0, false
+	}
+
+	values, ok := parts[2].([]interface{})
+	if !ok {
+		return nil, 0, false
+	}
+	for _, value := range values {
+		if _, ok := value.(string); !ok {
+			return nil, 0, false
+		}
+	}
+
+	return values, int(level), true
+}
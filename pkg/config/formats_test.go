@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadRaw(t *testing.T) {
+	want := map[string]interface{}{
+		"version":       1.0,
+		"commit_types":  []interface{}{"feat", map[string]interface{}{"name": "fix", "bump": "patch"}},
+		"skip_ci_types": []interface{}{"docs"},
+		"scope_modes":   map[string]interface{}{"feat": "required"},
+		"body_editor":   true,
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name: "JSON",
+			file: "commitsense.config.json",
+			content: `{"version": 1, "commit_types": ["feat", {"name": "fix", "bump": "patch"}], "skip_ci_types": ["docs"],
+				"scope_modes": {"feat": "required"}, "body_editor": true}`,
+			want: want,
+		},
+		{
+			name: "YAML",
+			file: ".commitsense.yaml",
+			content: `version: 1
+commit_types:
+  - feat
+  - name: fix
+    bump: patch
+skip_ci_types: [docs]
+scope_modes:
+  feat: required
+body_editor: true
+`,
+			want: want,
+		},
+		{
+			name: "TOML",
+			file: ".commitsense.toml",
+			content: `version = 1
+commit_types = ["feat", { name = "fix", bump = "patch" }]
+skip_ci_types = ["docs"]
+body_editor = true
+
+[scope_modes]
+feat = "required"
+`,
+			want: want,
+		},
+		{
+			name: "package.json",
+			file: "package.json",
+			content: `{"name": "app", "commitsense": {"version": 1, "commit_types": ["feat", {"name": "fix", "bump": "patch"}],
+				"skip_ci_types": ["docs"], "scope_modes": {"feat": "required"}, "body_editor": true}}`,
+			want: want,
+		},
+		{name: "empty JSON object", file: "commitsense.config.json", content: `null`, want: map[string]interface{}{}},
+		{name: "package.json without the key", file: "package.json", content: `{"name": "app"}`, wantErr: fs.ErrNotExist},
+		{name: "missing file", file: "commitsense.config.json", wantErr: fs.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if tt.content != "" {
+				writeTestFile(t, path, tt.content)
+			}
+
+			got, err := readRaw(path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRawInvalid(t *testing.T) {
+	for file, content := range map[string]string{
+		"commitsense.config.json": `{"version": `,
+		".commitsense.yaml":       "version: [",
+		"package.json":            `{"commitsense": ["feat"]}`,
+	} {
+		path := filepath.Join(t.TempDir(), file)
+		writeTestFile(t, path, content)
+
+		if raw, err := readRaw(path); err == nil || errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got %v (error %v) for the invalid %s, want an error", raw, err, file)
+		}
+	}
+}
+
+func TestFindFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{name: "none"},
+		{
+			name:  "first in the order of precedence",
+			files: map[string]string{".commitsense.toml": "", ".commitsense.yaml": "", "commitsense.config.json": "{}"},
+			want:  "commitsense.config.json",
+		},
+		{
+			name:  "package.json with the key",
+			files: map[string]string{"package.json": `{"commitsense": {}}`},
+			want:  "package.json",
+		},
+		{
+			name:  "package.json without the key",
+			files: map[string]string{"package.json": `{"name": "app"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, file), content)
+			}
+
+			want := ""
+			if tt.want != "" {
+				want = filepath.Join(dir, tt.want)
+			}
+			if got := findFile(dir, repositoryFileNames); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteRaw(t *testing.T) {
+	raw := map[string]interface{}{
+		"version":      1.0,
+		"commit_types": []interface{}{"feat", "fix"},
+		"scope_modes":  map[string]interface{}{"feat": "required"},
+	}
+
+	for _, file := range []string{"commitsense.config.json", ".commitsense.yaml", ".commitsense.toml"} {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), file)
+			writeTestFile(t, path, "")
+
+			if err := writeRaw(path, raw); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			got, err := readRaw(path)
+			if err != nil {
+				t.Fatalf("reading the written file: %v", err)
+			}
+			if !reflect.DeepEqual(got, raw) {
+				t.Errorf("got %#v, want %#v", got, raw)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "package.json")
+	writeTestFile(t, path, `{"commitsense": {}}`)
+	if err := writeRaw(path, raw); err == nil {
+		t.Error("writing package.json succeeded, want an error")
+	}
+}
+
+func TestReadCommitlintFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]interface{}
+	}{
+		{
+			name:    "type-enum and enforced scope-enum",
+			file:    ".commitlintrc.json",
+			content: `{"rules": {"type-enum": [2, "always", ["feat", "fix"]], "scope-enum": [2, "always", ["api", "web"]]}}`,
+			want: map[string]interface{}{
+				"version":        CurrentVersion,
+				"commit_types":   []interface{}{"feat", "fix"},
+				"scopes":         []interface{}{map[string]interface{}{"name": "api"}, map[string]interface{}{"name": "web"}},
+				"enforce_scopes": true,
+			},
+		},
+		{
+			name: "YAML without an extension and a scope-enum warning",
+			file: ".commitlintrc",
+			content: `rules:
+  scope-enum: [1, always, [api]]
+`,
+			want: map[string]interface{}{
+				"version":        CurrentVersion,
+				"scopes":         []interface{}{map[string]interface{}{"name": "api"}},
+				"enforce_scopes": false,
+			},
+		},
+		{
+			name: "rules that are disabled, never or malformed",
+			file: ".commitlintrc.yaml",
+			content: `extends: ["@commitlint/config-conventional"]
+rules:
+  type-enum: [0, always, [feat]]
+  scope-enum: [2, never, [api]]
+  subject-case: [2, always, lower-case]
+`,
+			want: map[string]interface{}{"version": CurrentVersion},
+		},
+		{
+			name:    "enum with values that are not strings",
+			file:    ".commitlintrc.json",
+			content: `{"rules": {"type-enum": [2, "always", ["feat", 1]]}}`,
+			want:    map[string]interface{}{"version": CurrentVersion},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeTestFile(t, path, tt.content)
+
+			got, err := readCommitlintFile(path)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
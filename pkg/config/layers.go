@@ -0,0 +1,332 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes utility functions for resolving the configuration from its layers: the built-in defaults,
+the global configuration file, the repository configuration file, the environment and the command line.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"commitsense/pkg/git"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Layers of the configuration, from the lowest to the highest precedence.
+const (
+	LayerDefault     = "default"
+	LayerGlobal      = "global"
+	LayerCommitlint  = "commitlint"
+	LayerRepository  = "repository"
+	LayerEnvironment = "environment"
+	LayerFlag        = "flag"
+)
+
+// envPrefix is the prefix of the environment variables overriding configuration keys,
+// e.g. COMMITSENSE_SKIP_CI_TYPES for skip_ci_types.
+const envPrefix = "COMMITSENSE_"
+
+var (
+	flagFile     string
+	flagSettings []string
+)
+
+// Origin describes the configuration layer a value was read from.
+type Origin struct {
+	// Layer is one of the configuration layers, e.g. LayerRepository.
+	Layer string
+	// Source is the file, environment variable or flag the value was read from, empty for the defaults.
+	Source string
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer
+	}
+	return o.Layer + " " + o.Source
+}
+
+// SetFlags sets the configuration file and the key=value settings given on the command line,
+// which take precedence over the other configuration layers.
+func SetFlags(file string, settings []string) error {
+	for _, setting := range settings {
+		if _, _, err := parseSetting(setting); err != nil {
+			return err
+		}
+	}
+
+	flagFile, flagSettings = file, settings
+
+	return nil
+}
+
+// GlobalFile returns the path of the global configuration file in $XDG_CONFIG_HOME/commitsense, where
+// XDG_CONFIG_HOME defaults to ~/.config. The file is config.json, config.yaml, config.yml or config.toml,
+// and config.json when none of them exists. An empty string is returned when there is no home directory.
+func GlobalFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	dir = filepath.Join(dir, "commitsense")
+
+	if path := findFile(dir, globalFileNames); path != "" {
+		return path
+	}
+
+	return filepath.Join(dir, globalFileNames[0])
+}
+
+// RepositoryFile returns the path of the configuration file in the root of the current Git repository:
+// commitsense.config.json, .commitsense.yaml, .commitsense.yml, .commitsense.toml or package.json with
+// a commitsense key, and commitsense.config.json when none of them exists.
+func RepositoryFile() string {
+	dir := RepositoryDir()
+	if path := findFile(dir, repositoryFileNames); path != "" {
+		return path
+	}
+
+	return filepath.Join(dir, configFileName)
+}
+
+// RepositoryDir returns the root directory of the current Git repository, or an empty string, the
+// working directory, when it is not inside a Git repository. The root is resolved on each call, as the
+// repository can be changed with git.Use.
+func RepositoryDir() string {
+	root, err := git.Current().Root()
+	if err != nil {
+		return ""
+	}
+
+	return root
+}
+
+// Keys returns the top-level keys of the configuration known to CommitSense.
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+
+	return keys
+}
+
+// Load reads the configuration layers and merges them into a raw configuration, returning it with the
+// origin of each of its keys. A layer replaces the keys it sets as a whole, and keys unknown to
+// CommitSense are kept. Files of older versions are migrated before they are merged.
+func Load() (map[string]interface{}, map[string]Origin, error) {
+	l := &layered{raw: map[string]interface{}{}, origins: map[string]Origin{}}
+
+	defaults, err := defaultRaw()
+	if err != nil {
+		return nil, nil, err
+	}
+	l.merge(defaults, Origin{Layer: LayerDefault})
+
+	if err := l.mergeFiles(); err != nil {
+		return nil, nil, err
+	}
+	l.mergeEnvironment()
+	if err := l.mergeFlags(); err != nil {
+		return nil, nil, err
+	}
+
+	return l.raw, l.origins, nil
+}
+
+// layered is a raw configuration being merged from its layers, with the origin of each of its keys.
+type layered struct {
+	raw     map[string]interface{}
+	origins map[string]Origin
+}
+
+// merge sets the keys of the layer, replacing the values of the lower layers.
+func (l *layered) merge(raw map[string]interface{}, origin Origin) {
+	for key, value := range raw {
+		l.raw[key] = value
+		l.origins[key] = origin
+	}
+}
+
+// mergeFiles merges the global, commitlint and repository configuration files that exist.
+func (l *layered) mergeFiles() error {
+	files := []struct {
+		layer string
+		path  string
+		read  func(string) (map[string]interface{}, error)
+	}{
+		{LayerGlobal, GlobalFile(), readLayerFile},
+		{LayerCommitlint, findFile(RepositoryDir(), commitlintFileNames), readCommitlintFile},
+		{LayerRepository, RepositoryFile(), readLayerFile},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+
+		raw, err := file.read(file.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		l.merge(raw, Origin{Layer: file.layer, Source: file.path})
+	}
+
+	return nil
+}
+
+// mergeEnvironment merges the COMMITSENSE_ environment variables.
+func (l *layered) mergeEnvironment() {
+	for _, key := range settableKeys() {
+		name := envPrefix + strings.ToUpper(key)
+		if value, ok := os.LookupEnv(name); ok {
+			l.merge(map[string]interface{}{key: parseValue(value)}, Origin{Layer: LayerEnvironment, Source: name})
+		}
+	}
+}
+
+// mergeFlags merges the file given with --config and then the settings given with --set.
+func (l *layered) mergeFlags() error {
+	if flagFile != "" {
+		raw, err := readLayerFile(flagFile)
+		if err != nil {
+			return err
+		}
+		l.merge(raw, Origin{Layer: LayerFlag, Source: "--config " + flagFile})
+	}
+
+	for _, setting := range flagSettings {
+		key, value, err := parseSetting(setting)
+		if err != nil {
+			return err
+		}
+		l.merge(map[string]interface{}{key: value}, Origin{Layer: LayerFlag, Source: "--set " + key})
+	}
+
+	return nil
+}
+
+// SortedKeys returns the keys of the raw configuration in alphabetical order.
+func SortedKeys(raw map[string]interface{}) []string {
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// readLayerFile reads and migrates a configuration file of a layer.
+func readLayerFile(path string) (map[string]interface{}, error) {
+	raw, err := readRaw(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := Migrate(raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return raw, nil
+}
+
+// defaultRaw returns the default configuration as a raw configuration.
+func defaultRaw() (map[string]interface{}, error) {
+	data, err := json.Marshal(NewDefault())
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	for key, value := range raw {
+		if value == nil {
+			delete(raw, key)
+		}
+	}
+
+	return raw, nil
+}
+
+// settableKeys returns the keys that can be set in the environment and on the command line.
+func settableKeys() []string {
+	var keys []string
+	for _, key := range Keys() {
+		if key != "version" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// parseSetting parses a key=value setting given on the command line.
+func parseSetting(setting string) (string, interface{}, error) {
+	key, value, found := strings.Cut(setting, "=")
+	if !found {
+		return "", nil, fmt.Errorf("invalid setting %q, expected the format key=value", setting)
+	}
+
+	key = strings.TrimSpace(key)
+	for _, known := range settableKeys() {
+		if key == known {
+			return key, parseValue(value), nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("unknown configuration key %q, expected one of [%s]", key, strings.Join(settableKeys(), ", "))
+}
+
+// parseValue parses a value given in the environment or on the command line. JSON values are decoded as
+// such, other values are read as comma separated lists, e.g. "feat,fix", or as objects when each item of
+// the list is a key=value pair, e.g. "feat=required,docs=forbidden".
+func parseValue(s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err == nil {
+		return value
+	}
+
+	items := []interface{}{}
+	object := map[string]interface{}{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		items = append(items, item)
+		if k, v, found := strings.Cut(item, "="); found {
+			object[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+
+	if len(object) > 0 && len(object) == len(items) {
+		return object
+	}
+
+	return items
+}
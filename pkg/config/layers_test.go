@@ -0,0 +1,180 @@
+package config
+
+import (
+	"commitsense/pkg/git"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useRepository makes the configuration layers read from a repository in a temporary directory and
+// from a global configuration directory of their own, returning the root of the repository.
+func useRepository(t *testing.T) string {
+	t.Helper()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, key := range settableKeys() {
+		name := envPrefix + strings.ToUpper(key)
+		if _, ok := os.LookupEnv(name); ok {
+			// Setenv restores the variable after the test.
+			t.Setenv(name, "")
+			_ = os.Unsetenv(name)
+		}
+	}
+
+	dir := t.TempDir()
+	git.Use(&git.MemoryRepository{Dir: dir})
+	t.Cleanup(func() {
+		git.Use(nil)
+		_ = SetFlags("", nil)
+	})
+
+	return dir
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	useRepository(t)
+
+	raw, origins, err := Load()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	defaults, _ := defaultRaw()
+	if !reflect.DeepEqual(raw, defaults) {
+		t.Errorf("got %v, want the defaults %v", raw, defaults)
+	}
+	for key, origin := range origins {
+		if origin != (Origin{Layer: LayerDefault}) {
+			t.Errorf("got origin %v of %s, want the defaults", origin, key)
+		}
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := useRepository(t)
+
+	global := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "commitsense", "config.json")
+	writeTestFile(t, global, `{"skip_ci_types": ["ci"], "body_editor": true, "scope_modes": {"feat": "required"}}`)
+
+	commitlint := filepath.Join(dir, ".commitlintrc.json")
+	writeTestFile(t, commitlint, `{"rules": {"type-enum": [2, "always", ["feat", "fix"]], "scope-enum": [1, "always", ["api"]]}}`)
+
+	repository := filepath.Join(dir, "commitsense.config.json")
+	writeTestFile(t, repository, `{"version": 1, "enforce_scopes": true, "custom": "kept"}`)
+
+	t.Setenv("COMMITSENSE_SCOPE_MODES", "feat=optional,docs=forbidden")
+
+	flag := filepath.Join(t.TempDir(), "flag.json")
+	writeTestFile(t, flag, `{"body_editor": false}`)
+	if err := SetFlags(flag, []string{"skip_ci_types=docs,ci"}); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	raw, origins, err := Load()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := map[string]interface{}{
+		"version":        1.0,
+		"commit_types":   []interface{}{"feat", "fix"},
+		"skip_ci_types":  []interface{}{"docs", "ci"},
+		"body_editor":    false,
+		"scope_modes":    map[string]interface{}{"feat": "optional", "docs": "forbidden"},
+		"scopes":         []interface{}{map[string]interface{}{"name": "api"}},
+		"enforce_scopes": true,
+		"custom":         "kept",
+	}
+	if !reflect.DeepEqual(raw, want) {
+		t.Errorf("got %v, want %v", raw, want)
+	}
+
+	wantOrigins := map[string]Origin{
+		"version":        {Layer: LayerRepository, Source: repository},
+		"commit_types":   {Layer: LayerCommitlint, Source: commitlint},
+		"skip_ci_types":  {Layer: LayerFlag, Source: "--set skip_ci_types"},
+		"body_editor":    {Layer: LayerFlag, Source: "--config " + flag},
+		"scope_modes":    {Layer: LayerEnvironment, Source: "COMMITSENSE_SCOPE_MODES"},
+		"scopes":         {Layer: LayerCommitlint, Source: commitlint},
+		"enforce_scopes": {Layer: LayerRepository, Source: repository},
+		"custom":         {Layer: LayerRepository, Source: repository},
+	}
+	if !reflect.DeepEqual(origins, wantOrigins) {
+		t.Errorf("got origins %v, want %v", origins, wantOrigins)
+	}
+}
+
+func TestLoadRefusesNewerVersions(t *testing.T) {
+	dir := useRepository(t)
+	writeTestFile(t, filepath.Join(dir, "commitsense.config.json"), `{"version": 99}`)
+
+	if _, _, err := Load(); err == nil {
+		t.Error("loading a configuration of a newer version succeeded, want an error")
+	}
+}
+
+func TestRepositoryDirFollowsGitUse(t *testing.T) {
+	first := useRepository(t)
+	if got := RepositoryDir(); got != first {
+		t.Errorf("got %q, want %q", got, first)
+	}
+
+	second := t.TempDir()
+	git.Use(&git.MemoryRepository{Dir: second})
+	if got := RepositoryDir(); got != second {
+		t.Errorf("got %q after git.Use, want %q", got, second)
+	}
+	if got, want := RepositoryFile(), filepath.Join(second, configFileName); got != want {
+		t.Errorf("got %q after git.Use, want %q", got, want)
+	}
+}
+
+func TestSetFlags(t *testing.T) {
+	useRepository(t)
+
+	for _, setting := range []string{"skip_ci_types", "unknown=1", "version=2"} {
+		if err := SetFlags("", []string{setting}); err == nil {
+			t.Errorf("setting %q succeeded, want an error", setting)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  interface{}
+	}{
+		{value: "true", want: true},
+		{value: "100", want: 100.0},
+		{value: `["feat", "fix"]`, want: []interface{}{"feat", "fix"}},
+		{value: `{"feat": "required"}`, want: map[string]interface{}{"feat": "required"}},
+		{value: `"quoted"`, want: "quoted"},
+		{value: "feat", want: []interface{}{"feat"}},
+		{value: "feat, fix,,docs", want: []interface{}{"feat", "fix", "docs"}},
+		{value: "feat=required, docs = forbidden", want: map[string]interface{}{"feat": "required", "docs": "forbidden"}},
+		{value: "feat=required,docs", want: []interface{}{"feat=required", "docs"}},
+		{value: "", want: []interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := parseValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
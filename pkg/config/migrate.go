@@ -0,0 +1,96 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes the migrations that upgrade configuration files written for older versions of CommitSense.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CurrentVersion is the newest version of the configuration format supported by CommitSense.
+const CurrentVersion = 1
+
+// ErrUnsupportedVersion is returned for configuration files written for a newer version of CommitSense.
+var ErrUnsupportedVersion = errors.New("unsupported configuration version")
+
+// migration upgrades a raw configuration from its version to the next one in place.
+type migration func(raw map[string]interface{}) error
+
+// migrations upgrade the configuration step by step, migrations[i] upgrading version i+1 to version i+2.
+// Version 1 is the first version of the format, so there is nothing to upgrade yet.
+var migrations []migration
+
+// Migrate upgrades the raw configuration to the current version in place, keeping the keys it does not
+// know about, and returns the version the configuration had. A configuration without a version is
+// treated as version 1.
+func Migrate(raw map[string]interface{}) (int, error) {
+	return migrate(raw, migrations)
+}
+
+// migrate upgrades the raw configuration in place with the migrations, the last of which upgrades it to
+// the current version.
+func migrate(raw map[string]interface{}, steps []migration) (int, error) {
+	version, err := rawVersion(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	current := len(steps) + 1
+	if version > current {
+		return version, fmt.Errorf(
+			"%w: the configuration has version %d but this version of CommitSense supports up to version %d, upgrade CommitSense to use it",
+			ErrUnsupportedVersion, version, current,
+		)
+	}
+
+	for v := version; v < current; v++ {
+		if err := steps[v-1](raw); err != nil {
+			return version, fmt.Errorf("migrating the configuration from version %d to %d: %w", v, v+1, err)
+		}
+		raw["version"] = v + 1
+	}
+
+	return version, nil
+}
+
+// MigrateFile upgrades the configuration file to the current version and rewrites it, returning the
+// version the file had. A file that is already up to date is left untouched.
+func MigrateFile(path string) (int, error) {
+	raw, err := readRaw(path)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := Migrate(raw)
+	if err != nil {
+		return version, fmt.Errorf("%s: %w", path, err)
+	}
+	if version == CurrentVersion {
+		return version, nil
+	}
+
+	return version, writeRaw(path, raw)
+}
+
+func rawVersion(raw map[string]interface{}) (int, error) {
+	value, ok := raw["version"]
+	if !ok || value == nil {
+		return 1, nil
+	}
+
+	number, ok := value.(float64)
+	if !ok || number != float64(int(number)) {
+		return 0, fmt.Errorf("version must be a whole number, got %v", value)
+	}
+
+	if number < 1 {
+		return 1, nil
+	}
+
+	return int(number), nil
+}
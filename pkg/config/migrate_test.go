@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateCurrentVersion(t *testing.T) {
+	if want := len(migrations) + 1; CurrentVersion != want {
+		t.Errorf("got CurrentVersion %d, want %d for %d migrations", CurrentVersion, want, len(migrations))
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	// The steps upgrade version 1 to 2 and version 2 to 3, recording the versions they were run on.
+	steps := []migration{
+		func(raw map[string]interface{}) error {
+			raw["steps"] = append(raw["steps"].([]int), 1)
+			return nil
+		},
+		func(raw map[string]interface{}) error {
+			raw["steps"] = append(raw["steps"].([]int), 2)
+			return nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		version     interface{}
+		wantVersion int
+		wantSteps   []int
+		wantStamp   interface{}
+		wantErr     error
+	}{
+		{name: "without a version", wantVersion: 1, wantSteps: []int{1, 2}, wantStamp: 3},
+		{name: "version 1", version: float64(1), wantVersion: 1, wantSteps: []int{1, 2}, wantStamp: 3},
+		{name: "version 2", version: float64(2), wantVersion: 2, wantSteps: []int{2}, wantStamp: 3},
+		{name: "current version", version: float64(3), wantVersion: 3, wantSteps: []int{}, wantStamp: float64(3)},
+		{
+			name:        "newer version",
+			version:     float64(4),
+			wantVersion: 4,
+			wantSteps:   []int{},
+			wantStamp:   float64(4),
+			wantErr:     ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]interface{}{"steps": []int{}, "unknown": "kept"}
+			if tt.version != nil {
+				raw["version"] = tt.version
+			}
+
+			version, err := migrate(raw, steps)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("got version %d, want %d", version, tt.wantVersion)
+			}
+			if !reflect.DeepEqual(raw["steps"], tt.wantSteps) {
+				t.Errorf("got steps %v, want %v", raw["steps"], tt.wantSteps)
+			}
+			if raw["unknown"] != "kept" {
+				t.Errorf("got unknown key %v, want it kept", raw["unknown"])
+			}
+			if raw["version"] != tt.wantStamp {
+				t.Errorf("got version stamp %v, want %v", raw["version"], tt.wantStamp)
+			}
+		})
+	}
+}
+
+func TestMigrateInvalidVersion(t *testing.T) {
+	for _, version := range []interface{}{"1", 1.5, true} {
+		if _, err := Migrate(map[string]interface{}{"version": version}); err == nil {
+			t.Errorf("migrating version %v succeeded, want an error", version)
+		}
+	}
+}
+
+func TestMigrateStepError(t *testing.T) {
+	errStep := errors.New("step failed")
+	raw := map[string]interface{}{}
+
+	_, err := migrate(raw, []migration{func(map[string]interface{}) error { return errStep }})
+	if !errors.Is(err, errStep) {
+		t.Errorf("got error %v, want the error of the step", err)
+	}
+	if _, ok := raw["version"]; ok {
+		t.Errorf("got version stamp %v after a failed step, want none", raw["version"])
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes the configuration presets offered when creating a new configuration file.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset represents a ready-made configuration for a commit convention.
+type Preset struct {
+	Name        string
+	Description string
+	commitTypes []CommitType
+	skipCITypes []string
+}
+
+// Presets lists the available configuration presets, the first one being the default.
+var Presets = []Preset{
+	{
+		Name:        "conventional",
+		Description: "The commit types of the Conventional Commits specification",
+		commitTypes: defaultCommitTypes,
+		skipCITypes: defaultSkipCITypes,
+	},
+	{
+		Name:        "angular",
+		Description: "The commit types of the Angular commit message guidelines",
+		commitTypes: []CommitType{
+			{Name: "build"}, {Name: "ci"}, {Name: "docs"}, {Name: "feat"}, {Name: "fix"},
+			{Name: "perf"}, {Name: "refactor"}, {Name: "test"},
+		},
+		skipCITypes: []string{"docs"},
+	},
+	{
+		Name:        "gitmoji",
+		Description: "The Conventional Commits types with gitmoji emoji",
+		commitTypes: []CommitType{
+			{Name: "feat", Emoji: "✨"},
+			{Name: "fix", Emoji: "🐛"},
+			{Name: "docs", Emoji: "📝"},
+			{Name: "style", Emoji: "🎨"},
+			{Name: "refactor", Emoji: "♻️"},
+			{Name: "perf", Emoji: "⚡️"},
+			{Name: "test", Emoji: "✅"},
+			{Name: "build", Emoji: "📦️"},
+			{Name: "ci", Emoji: "👷"},
+			{Name: "chore", Emoji: "🔧"},
+			{Name: "revert", Emoji: "⏪️"},
+		},
+		skipCITypes: []string{"docs"},
+	},
+	{
+		Name:        "minimal",
+		Description: "Only the feat, fix and chore commit types",
+		commitTypes: []CommitType{{Name: "feat"}, {Name: "fix"}, {Name: "chore"}},
+	},
+}
+
+// Config returns a new configuration with the commit types of the preset.
+func (p Preset) Config() *Config {
+	return &Config{
+		Version:     CurrentVersion,
+		CommitTypes: append([]CommitType{}, p.commitTypes...),
+		SkipCITypes: append([]string{}, p.skipCITypes...),
+	}
+}
+
+// LookupPreset returns the preset with the name.
+func LookupPreset(name string) (Preset, error) {
+	names := make([]string, 0, len(Presets))
+	for _, preset := range Presets {
+		if preset.Name == name {
+			return preset, nil
+		}
+		names = append(names, preset.Name)
+	}
+
+	return Preset{}, fmt.Errorf("unknown preset %q, expected one of [%s]", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestLookupPreset(t *testing.T) {
+	for _, preset := range Presets {
+		got, err := LookupPreset(preset.Name)
+		if err != nil {
+			t.Fatalf("got error %v for %q", err, preset.Name)
+		}
+
+		cfg := got.Config()
+		if cfg.Version != CurrentVersion || len(cfg.CommitTypes) == 0 {
+			t.Errorf("got %+v for %q, want the current version and commit types", cfg, preset.Name)
+		}
+
+		// Changing the configuration must not change the preset.
+		cfg.CommitTypes[0].Name = "changed"
+		if preset.Config().CommitTypes[0].Name == "changed" {
+			t.Errorf("changing the configuration of %q changed the preset", preset.Name)
+		}
+	}
+
+	if _, err := LookupPreset("unknown"); err == nil {
+		t.Error("looking up an unknown preset succeeded, want an error")
+	}
+}
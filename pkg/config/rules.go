@@ -0,0 +1,81 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes the configuration of the commit message lint rules.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Levels of the lint rules.
+const (
+	LevelOff   = "off"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// RuleConfig configures a lint rule. Rules that are not configured use their default level and options.
+//
+// In the configuration file a rule is either a plain string, the level of the rule,
+// or an object with the fields below.
+type RuleConfig struct {
+	Level string `json:"level,omitempty" jsonschema:"enum=off,enum=warn,enum=error"`
+	// Options override the default options of the rule, e.g. {"max": 100} for header-max-length.
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// ruleConfigObject has the fields of RuleConfig without its JSON methods.
+type ruleConfigObject RuleConfig
+
+// UnmarshalJSON decodes a rule configuration from either a level or an object.
+func (r *RuleConfig) UnmarshalJSON(data []byte) error {
+	var level string
+	if err := json.Unmarshal(data, &level); err == nil {
+		*r = RuleConfig{Level: level}
+		return nil
+	}
+
+	var object ruleConfigObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("rule must be a level or an object: %w", err)
+	}
+
+	*r = RuleConfig(object)
+
+	return nil
+}
+
+// JSONSchema describes the two forms of a rule configuration in the configuration file.
+func (RuleConfig) JSONSchema() map[string]interface{} {
+	object := structSchema(reflect.TypeOf(ruleConfigObject{}))
+	level, _ := object["properties"].(map[string]interface{})["level"].(map[string]interface{})
+
+	return map[string]interface{}{
+		"oneOf": []interface{}{level, object},
+	}
+}
+
+// decodeRules decodes the rule configurations read from the configuration file.
+func decodeRules(raw interface{}) (map[string]RuleConfig, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules map[string]RuleConfig
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,262 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes the JSON Schema of the configuration file, generated from the Config struct, and the
+validation of configuration files against it.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// schemaProvider is implemented by types that describe their own JSON Schema,
+// e.g. types with a custom JSON encoding.
+type schemaProvider interface {
+	JSONSchema() map[string]interface{}
+}
+
+var schemaProviderType = reflect.TypeOf((*schemaProvider)(nil)).Elem()
+
+// Schema returns the JSON Schema of the configuration file. The schema is generated from the json
+// and jsonschema tags of the Config struct, e.g. `jsonschema:"required,enum=major,enum=minor"`.
+func Schema() map[string]interface{} {
+	schema := schemaOf(reflect.TypeOf(Config{}))
+
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "CommitSense configuration"
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	properties["$schema"] = map[string]interface{}{"type": "string"}
+
+	return schema
+}
+
+func schemaOf(t reflect.Type) map[string]interface{} {
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(schemaProvider).JSONSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []interface{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		property := schemaOf(field.Type)
+		if applySchemaTag(property, field.Tag.Get("jsonschema")) {
+			required = append(required, name)
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// applySchemaTag applies the options of a jsonschema tag to the schema of a field, and returns whether
+// the field is required. The enum option applies to the elements of arrays and the values of maps.
+func applySchemaTag(schema map[string]interface{}, tag string) bool {
+	required := false
+
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(option, "=")
+
+		switch key {
+		case "required":
+			required = true
+		case "enum":
+			target := schema
+			if items, ok := schema["items"].(map[string]interface{}); ok {
+				target = items
+			} else if values, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+				target = values
+			}
+			enum, _ := target["enum"].([]interface{})
+			target["enum"] = append(enum, value)
+		case "minItems", "minLength":
+			if n, err := strconv.Atoi(value); err == nil {
+				schema[key] = float64(n)
+			}
+		}
+	}
+
+	return required
+}
+
+// validateSchema validates a raw configuration value against the subset of JSON Schema generated by
+// Schema, reporting the problems found at the JSON pointer of the value.
+func validateSchema(schema map[string]interface{}, value interface{}, pointer string) []Problem {
+	if branches, ok := schema["oneOf"].([]interface{}); ok {
+		return validateOneOf(branches, value, pointer)
+	}
+
+	if schemaType, ok := schema["type"]; ok && !matchesType(schemaType, value) {
+		return []Problem{{Pointer: pointer, Message: fmt.Sprintf("must be of type %v", schemaType)}}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		var allowed []string
+		for _, e := range enum {
+			allowed = append(allowed, fmt.Sprint(e))
+		}
+		got, _ := json.Marshal(value)
+		return []Problem{{Pointer: pointer, Message: fmt.Sprintf("must be one of [%s], got %s", strings.Join(allowed, ", "), got)}}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if minLength, ok := schema["minLength"].(float64); ok && float64(len(v)) < minLength {
+			return []Problem{{Pointer: pointer, Message: "must not be empty"}}
+		}
+	case []interface{}:
+		return validateArray(schema, v, pointer)
+	case map[string]interface{}:
+		return validateObject(schema, v, pointer)
+	}
+
+	return nil
+}
+
+// validateOneOf validates the value against the first of the branches of its type.
+func validateOneOf(branches []interface{}, value interface{}, pointer string) []Problem {
+	var names []string
+	for _, branch := range branches {
+		branch, _ := branch.(map[string]interface{})
+		if matchesType(branch["type"], value) {
+			return validateSchema(branch, value, pointer)
+		}
+		names = append(names, fmt.Sprint(branch["type"]))
+	}
+
+	return []Problem{{Pointer: pointer, Message: "must be a " + strings.Join(names, " or an ")}}
+}
+
+// validateArray validates the length and the items of an array.
+func validateArray(schema map[string]interface{}, v []interface{}, pointer string) []Problem {
+	var problems []Problem
+
+	if minItems, ok := schema["minItems"].(float64); ok && float64(len(v)) < minItems {
+		problems = append(problems, Problem{Pointer: pointer, Message: fmt.Sprintf("must have at least %v item(s)", minItems)})
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range v {
+			problems = append(problems, validateSchema(items, item, pointer+"/"+strconv.Itoa(i))...)
+		}
+	}
+
+	return problems
+}
+
+// validateObject validates the required keys and the values of an object, in the order of the keys.
+func validateObject(schema map[string]interface{}, v map[string]interface{}, pointer string) []Problem {
+	var problems []Problem
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				problems = append(problems, Problem{Pointer: pointer, Message: fmt.Sprintf("missing required key %q", name)})
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, key := range SortedKeys(v) {
+		child := pointer + "/" + escapePointer(key)
+		if property, ok := properties[key].(map[string]interface{}); ok {
+			problems = append(problems, validateSchema(property, v[key], child)...)
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				problems = append(problems, Problem{Pointer: child, Message: fmt.Sprintf("unknown key %q", key)})
+			}
+		case map[string]interface{}:
+			problems = append(problems, validateSchema(additional, v[key], child)...)
+		}
+	}
+
+	return problems
+}
+
+func matchesType(schemaType interface{}, value interface{}) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// escapePointer escapes a key for use in a JSON pointer as described in RFC 6901.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
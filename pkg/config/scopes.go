@@ -0,0 +1,177 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes utility functions for working with the configured commit scopes.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Scope modes of a commit type.
+const (
+	ScopeRequired  = "required"
+	ScopeOptional  = "optional"
+	ScopeForbidden = "forbidden"
+)
+
+// Errors returned by ValidateScope.
+var (
+	ErrScopeRequired  = errors.New("scope is required")
+	ErrScopeForbidden = errors.New("scope is not allowed")
+	ErrUnknownScope   = errors.New("scope is not configured")
+)
+
+// Scope represents a commit scope, the part of the codebase affected by a commit.
+type Scope struct {
+	Name        string `json:"name" jsonschema:"required,minLength=1"`
+	Description string `json:"description,omitempty"`
+	// Paths are glob patterns of the files belonging to the scope, e.g. "pkg/config/**".
+	Paths []string `json:"paths,omitempty"`
+}
+
+// ScopeNames returns the names of the configured scopes.
+func (c *Config) ScopeNames() []string {
+	names := make([]string, 0, len(c.Scopes))
+	for _, scope := range c.Scopes {
+		names = append(names, scope.Name)
+	}
+	return names
+}
+
+// HasScope reports whether the scope is configured.
+func (c *Config) HasScope(name string) bool {
+	for _, scope := range c.Scopes {
+		if scope.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeMode returns whether a scope is required, optional or forbidden for the commit type.
+// Commit types without a configured mode have an optional scope.
+func (c *Config) ScopeMode(commitType string) string {
+	switch mode := c.ScopeModes[commitType]; mode {
+	case ScopeRequired, ScopeForbidden:
+		return mode
+	default:
+		return ScopeOptional
+	}
+}
+
+// ValidateScope checks that the scope is allowed for the commit type. An empty scope means no scope.
+// When EnforceScopes is set, only the configured scopes are allowed.
+func (c *Config) ValidateScope(commitType string, scope string) error {
+	switch mode := c.ScopeMode(commitType); {
+	case scope == "" && mode == ScopeRequired:
+		return fmt.Errorf("%w for commit type %q", ErrScopeRequired, commitType)
+	case scope == "":
+		return nil
+	case mode == ScopeForbidden:
+		return fmt.Errorf("%w for commit type %q", ErrScopeForbidden, commitType)
+	case c.EnforceScopes && !c.HasScope(scope):
+		return fmt.Errorf("%w: %q must be one of [%s]", ErrUnknownScope, scope, strings.Join(c.ScopeNames(), ", "))
+	}
+
+	return nil
+}
+
+// SuggestScope proposes a scope for a commit changing the given files, with paths relative to the
+// repository root: the configured scope matching most of the files, see MatchScope. If no scope matches,
+// the common top-level directory of the files is proposed, unless the scopes are enforced and it is not
+// a configured scope. An empty string is returned when there is nothing to propose.
+func (c *Config) SuggestScope(files []string) string {
+	if scope := c.MatchScope(files); scope != "" {
+		return scope
+	}
+
+	directory := commonTopLevelDirectory(files)
+	if c.EnforceScopes && !c.HasScope(directory) {
+		return ""
+	}
+
+	return directory
+}
+
+// MatchScope returns the configured scope whose paths match most of the files, the first of the scopes
+// on a tie, or an empty string when the paths of no scope match the files.
+func (c *Config) MatchScope(files []string) string {
+	matches := map[string]int{}
+	for _, file := range files {
+		if scope, ok := c.scopeOfFile(file); ok {
+			matches[scope]++
+		}
+	}
+
+	match, most := "", 0
+	for _, scope := range c.Scopes {
+		if matches[scope.Name] > most {
+			match, most = scope.Name, matches[scope.Name]
+		}
+	}
+
+	return match
+}
+
+// scopeOfFile returns the first configured scope with a path pattern matching the file.
+func (c *Config) scopeOfFile(file string) (string, bool) {
+	for _, scope := range c.Scopes {
+		for _, pattern := range scope.Paths {
+			if matchGlob(pattern, file) {
+				return scope.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// commonTopLevelDirectory returns the top-level directory shared by all of the files,
+// or an empty string if the files are in different directories or in the repository root.
+func commonTopLevelDirectory(files []string) string {
+	var directory string
+	for i, file := range files {
+		top, _, found := strings.Cut(file, "/")
+		if !found || (i > 0 && top != directory) {
+			return ""
+		}
+		directory = top
+	}
+	return directory
+}
+
+// matchGlob reports whether the slash separated path matches the glob pattern. In addition to the
+// syntax of path.Match, a "**" path segment matches any number of directories.
+func matchGlob(pattern string, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "pkg/config/**", name: "pkg/config/config.go", want: true},
+		{pattern: "pkg/config/**", name: "pkg/config/sub/dir/file.go", want: true},
+		{pattern: "pkg/config/**", name: "pkg/config", want: true},
+		{pattern: "pkg/config/**", name: "pkg/configs/file.go"},
+		{pattern: "**/*.md", name: "README.md", want: true},
+		{pattern: "**/*.md", name: "docs/guide/intro.md", want: true},
+		{pattern: "**/*.md", name: "docs/guide/intro.go"},
+		{pattern: "cmd/*.go", name: "cmd/root.go", want: true},
+		{pattern: "cmd/*.go", name: "cmd/sub/root.go"},
+		{pattern: "pkg/**/testdata/*", name: "pkg/hunk/testdata/split.diff", want: true},
+		{pattern: "pkg/**/testdata/*", name: "pkg/testdata/split.diff", want: true},
+		{pattern: "go.mod", name: "go.mod", want: true},
+		{pattern: "go.mod", name: "sub/go.mod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestScope(t *testing.T) {
+	scopes := []Scope{
+		{Name: "config", Paths: []string{"pkg/config/**"}},
+		{Name: "cli", Paths: []string{"cmd/**", "main.go"}},
+		{Name: "docs", Paths: []string{"**/*.md"}},
+	}
+
+	tests := []struct {
+		name      string
+		files     []string
+		enforce   bool
+		wantMatch string
+		want      string
+	}{
+		{name: "no files"},
+		{name: "one scope", files: []string{"pkg/config/config.go", "pkg/config/layers.go"}, wantMatch: "config", want: "config"},
+		{
+			name:      "scope matching most of the files",
+			files:     []string{"cmd/root.go", "pkg/config/config.go", "main.go"},
+			wantMatch: "cli",
+			want:      "cli",
+		},
+		{name: "first scope on a tie", files: []string{"cmd/root.go", "pkg/config/config.go"}, wantMatch: "config", want: "config"},
+		{name: "first matching pattern of the scopes", files: []string{"pkg/config/README.md"}, wantMatch: "config", want: "config"},
+		{name: "common top-level directory", files: []string{"pkg/git/command.go", "pkg/lint/lint.go"}, want: "pkg"},
+		{name: "common top-level directory not configured", files: []string{"pkg/git/command.go"}, enforce: true},
+		{name: "files in different directories", files: []string{"pkg/git/command.go", "internal/printer/printer.go"}},
+		{name: "files in the root", files: []string{"go.mod", "go.sum"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Scopes: scopes, EnforceScopes: tt.enforce}
+
+			if got := cfg.MatchScope(tt.files); got != tt.wantMatch {
+				t.Errorf("got match %q, want %q", got, tt.wantMatch)
+			}
+			if got := cfg.SuggestScope(tt.files); got != tt.want {
+				t.Errorf("got suggestion %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateScope(t *testing.T) {
+	cfg := &Config{
+		Scopes:        []Scope{{Name: "api"}, {Name: "web"}},
+		EnforceScopes: true,
+		ScopeModes:    map[string]string{"feat": ScopeRequired, "docs": ScopeForbidden, "fix": "unknown"},
+	}
+
+	tests := []struct {
+		commitType string
+		scope      string
+		want       error
+	}{
+		{commitType: "feat", scope: "api"},
+		{commitType: "feat", want: ErrScopeRequired},
+		{commitType: "feat", scope: "db", want: ErrUnknownScope},
+		{commitType: "docs"},
+		{commitType: "docs", scope: "api", want: ErrScopeForbidden},
+		{commitType: "fix"},
+		{commitType: "fix", scope: "web"},
+		{commitType: "chore", scope: "db", want: ErrUnknownScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.commitType+" "+tt.scope, func(t *testing.T) {
+			if err := cfg.ValidateScope(tt.commitType, tt.scope); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	cfg.EnforceScopes = false
+	if err := cfg.ValidateScope("chore", "db"); err != nil {
+		t.Errorf("got error %v for a scope that is not configured, want none without enforce_scopes", err)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes utility functions for working with the configured commit types.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// bumpNone is the bump level of commit types that do not bump the version.
+const bumpNone = "none"
+
+// defaultTypeDescriptions describes the commit types of the Conventional Commits specification,
+// used for the commit types configured without a description.
+var defaultTypeDescriptions = map[string]string{
+	"feat":     "A new feature or enhancement",
+	"fix":      "A bug fix",
+	"docs":     "Documentation updates",
+	"style":    "Code style and formatting changes, not affecting functionality",
+	"refactor": "Code refactorings without adding new features or fixing bugs",
+	"perf":     "Performance improvements",
+	"test":     "Adding or modifying tests",
+	"build":    "Build-related changes, e.g. dependencies",
+	"ci":       "Continuous integration and deployment changes",
+	"chore":    "Routine tasks and maintenance",
+	"revert":   "Reverting a previous commit",
+}
+
+// CommitType represents a commit type and how commits of the type are presented.
+//
+// In the configuration file a commit type is either a plain string, the name of the type,
+// or an object with the fields below.
+type CommitType struct {
+	Name        string `json:"name" jsonschema:"required,minLength=1"`
+	Description string `json:"description,omitempty"`
+	Emoji       string `json:"emoji,omitempty"`
+	// Aliases are alternative names of the shorthand command of the type, e.g. "feature" for "feat".
+	Aliases []string `json:"aliases,omitempty"`
+	// ChangelogTitle is the title of the changelog section of the type. The standard types
+	// feat, fix, perf and revert have a title by default, other types are left out of the changelog.
+	ChangelogTitle string `json:"changelog_title,omitempty"`
+	// Bump is the version bump caused by the type: major, minor, patch or none. By default feat
+	// bumps the minor version, fix and perf the patch version, and other types do not bump the version.
+	Bump string `json:"bump,omitempty" jsonschema:"enum=major,enum=minor,enum=patch,enum=none"`
+	// Hidden leaves the commits of the type out of the changelog, and the type out of the commit type
+	// selection and the help of the shorthand commands. Commits of the type can still be created.
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// commitTypeObject has the fields of CommitType without its JSON methods.
+type commitTypeObject CommitType
+
+// UnmarshalJSON decodes a commit type from either a string or an object.
+func (t *CommitType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = CommitType{Name: name}
+		return nil
+	}
+
+	var object commitTypeObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("commit type must be a string or an object: %w", err)
+	}
+
+	*t = CommitType(object)
+
+	return nil
+}
+
+// MarshalJSON encodes a commit type with only a name as a plain string, and other commit types as objects,
+// keeping configuration files using the plain string format unchanged.
+func (t CommitType) MarshalJSON() ([]byte, error) {
+	if t.isNameOnly() {
+		return json.Marshal(t.Name)
+	}
+	return json.Marshal(commitTypeObject(t))
+}
+
+func (t CommitType) isNameOnly() bool {
+	return t.Description == "" && t.Emoji == "" && len(t.Aliases) == 0 && t.ChangelogTitle == "" &&
+		t.Bump == "" && !t.Hidden
+}
+
+// JSONSchema describes the two forms of a commit type in the configuration file.
+func (CommitType) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string", "minLength": float64(1)},
+			structSchema(reflect.TypeOf(commitTypeObject{})),
+		},
+	}
+}
+
+// Summary returns the description of the commit type, falling back to the description of the
+// standard Conventional Commits types.
+func (t CommitType) Summary() string {
+	if t.Description != "" {
+		return t.Description
+	}
+	return defaultTypeDescriptions[t.Name]
+}
+
+// decodeCommitTypes decodes the commit types read from the configuration file.
+func decodeCommitTypes(raw interface{}) ([]CommitType, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitTypes []CommitType
+	if err := json.Unmarshal(data, &commitTypes); err != nil {
+		return nil, fmt.Errorf("invalid commit_types: %w", err)
+	}
+
+	return commitTypes, nil
+}
+
+// TypeNames returns the names of the configured commit types.
+func (c *Config) TypeNames() []string {
+	names := make([]string, 0, len(c.CommitTypes))
+	for _, commitType := range c.CommitTypes {
+		names = append(names, commitType.Name)
+	}
+	return names
+}
+
+// VisibleTypes returns the configured commit types that are not hidden.
+func (c *Config) VisibleTypes() []CommitType {
+	var types []CommitType
+	for _, commitType := range c.CommitTypes {
+		if !commitType.Hidden {
+			types = append(types, commitType)
+		}
+	}
+	return types
+}
+
+// LookupType returns the configured commit type with the name, and whether it was found.
+func (c *Config) LookupType(name string) (CommitType, bool) {
+	for _, commitType := range c.CommitTypes {
+		if commitType.Name == name {
+			return commitType, true
+		}
+	}
+	return CommitType{}, false
+}
+
+// ChangelogTitle returns the changelog section title of the commit type,
+// and whether commits of the type are included in the changelog.
+func (c *Config) ChangelogTitle(commitType string) (string, bool) {
+	t, _ := c.LookupType(commitType)
+	if t.Hidden {
+		return "", false
+	}
+
+	title := t.ChangelogTitle
+	if title == "" {
+		title = defaultChangelogSections[commitType]
+	}
+
+	return title, title != ""
+}
+
+// BumpLevel returns the version bump level of the commit type: major, minor, patch or none.
+func (c *Config) BumpLevel(commitType string) string {
+	if t, ok := c.LookupType(commitType); ok && t.Bump != "" {
+		return t.Bump
+	}
+	return defaultBumpLevel(commitType)
+}
+
+// defaultBumpLevel returns the version bump level of the standard commit types.
+func defaultBumpLevel(commitType string) string {
+	if level, ok := defaultBumpTypes[commitType]; ok {
+		return level
+	}
+	return bumpNone
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommitTypes(t *testing.T) {
+	cfg := &Config{CommitTypes: []CommitType{
+		{Name: "feat"},
+		{Name: "fix", ChangelogTitle: "Fixes", Bump: "minor"},
+		{Name: "perf", Hidden: true},
+		{Name: "docs", Bump: "patch"},
+		{Name: "wip", Hidden: true, ChangelogTitle: "Work in progress"},
+	}}
+
+	if got, want := cfg.TypeNames(), []string{"feat", "fix", "perf", "docs", "wip"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got type names %v, want %v", got, want)
+	}
+
+	var visible []string
+	for _, commitType := range cfg.VisibleTypes() {
+		visible = append(visible, commitType.Name)
+	}
+	if want := []string{"feat", "fix", "docs"}; !reflect.DeepEqual(visible, want) {
+		t.Errorf("got visible types %v, want %v", visible, want)
+	}
+
+	tests := []struct {
+		commitType  string
+		wantTitle   string
+		wantInclude bool
+		wantBump    string
+	}{
+		{commitType: "feat", wantTitle: "Features", wantInclude: true, wantBump: "minor"},
+		{commitType: "fix", wantTitle: "Fixes", wantInclude: true, wantBump: "minor"},
+		{commitType: "perf", wantBump: "patch"},
+		{commitType: "docs", wantBump: "patch"},
+		{commitType: "wip", wantBump: "none"},
+		{commitType: "revert", wantTitle: "Reverts", wantInclude: true, wantBump: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.commitType, func(t *testing.T) {
+			title, include := cfg.ChangelogTitle(tt.commitType)
+			if title != tt.wantTitle || include != tt.wantInclude {
+				t.Errorf("got changelog title %q, %v, want %q, %v", title, include, tt.wantTitle, tt.wantInclude)
+			}
+			if got := cfg.BumpLevel(tt.commitType); got != tt.wantBump {
+				t.Errorf("got bump %q, want %q", got, tt.wantBump)
+			}
+		})
+	}
+}
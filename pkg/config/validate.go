@@ -0,0 +1,190 @@
+/*
+Package config provides functionality for reading, creating and modifying configuration files for CommitSense.
+
+This file includes the validation of configurations, reporting each problem found with a JSON pointer to the
+value it concerns.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package config
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Problem represents an issue found in a configuration.
+type Problem struct {
+	// Pointer is the JSON pointer (RFC 6901) of the value with the issue, e.g. "/commit_types/2".
+	Pointer string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Pointer + ": " + p.Message
+}
+
+// Key returns the top-level configuration key the problem concerns.
+func (p Problem) Key() string {
+	key, _, _ := strings.Cut(strings.TrimPrefix(p.Pointer, "/"), "/")
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+}
+
+// Validate checks a raw configuration against the JSON Schema of the configuration file, and for the
+// inconsistencies the schema cannot express, such as duplicate commit types and skip ci types that are
+// not configured commit types.
+func Validate(raw map[string]interface{}) []Problem {
+	problems := validateSchema(Schema(), raw, "")
+	problems = append(problems, validateCommitTypes(raw)...)
+	problems = append(problems, validateScopes(raw)...)
+
+	return problems
+}
+
+// LoadFile reads and migrates a single configuration file, filling the keys missing from the file with
+// their default values, so that the file can be validated on its own.
+func LoadFile(path string) (map[string]interface{}, error) {
+	raw, err := readLayerFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := defaultRaw()
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range defaults {
+		if _, ok := raw[key]; !ok {
+			raw[key] = value
+		}
+	}
+
+	return raw, nil
+}
+
+// validateCommitTypes reports duplicate commit types and aliases, and the skip ci types and scope modes
+// of commit types that are not configured.
+func validateCommitTypes(raw map[string]interface{}) []Problem {
+	var problems []Problem
+
+	defined := map[string]string{}
+	define := func(name string, pointer string, kind string) {
+		if name == "" {
+			return
+		}
+		if first, ok := defined[name]; ok {
+			problems = append(problems, Problem{
+				Pointer: pointer,
+				Message: fmt.Sprintf("duplicate %s %q, already defined at %s", kind, name, first),
+			})
+			return
+		}
+		defined[name] = pointer
+	}
+
+	types := map[string]bool{}
+	entries, _ := raw["commit_types"].([]interface{})
+	for i, entry := range entries {
+		pointer := "/commit_types/" + strconv.Itoa(i)
+
+		switch value := entry.(type) {
+		case string:
+			define(value, pointer, "commit type")
+			types[value] = true
+		case map[string]interface{}:
+			name, _ := value["name"].(string)
+			define(name, pointer, "commit type")
+			types[name] = true
+
+			aliases, _ := value["aliases"].([]interface{})
+			for j, alias := range aliases {
+				alias, _ := alias.(string)
+				define(alias, pointer+"/aliases/"+strconv.Itoa(j), "commit type alias")
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return problems
+	}
+
+	return append(problems, validateTypeReferences(raw, types)...)
+}
+
+// validateTypeReferences reports the skip ci types and scope modes of commit types that are not configured.
+func validateTypeReferences(raw map[string]interface{}, types map[string]bool) []Problem {
+	var problems []Problem
+
+	skipCITypes, _ := raw["skip_ci_types"].([]interface{})
+	for i, entry := range skipCITypes {
+		if name, ok := entry.(string); ok && !types[name] {
+			problems = append(problems, Problem{
+				Pointer: "/skip_ci_types/" + strconv.Itoa(i),
+				Message: fmt.Sprintf("%q is not a configured commit type", name),
+			})
+		}
+	}
+
+	scopeModes, _ := raw["scope_modes"].(map[string]interface{})
+	for _, name := range SortedKeys(scopeModes) {
+		if !types[name] {
+			problems = append(problems, Problem{
+				Pointer: "/scope_modes/" + escapePointer(name),
+				Message: fmt.Sprintf("%q is not a configured commit type", name),
+			})
+		}
+	}
+
+	return problems
+}
+
+// validateScopes reports duplicate scopes and malformed path patterns of the scopes.
+func validateScopes(raw map[string]interface{}) []Problem {
+	var problems []Problem
+
+	defined := map[string]string{}
+	scopes, _ := raw["scopes"].([]interface{})
+	for i, entry := range scopes {
+		scope, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pointer := "/scopes/" + strconv.Itoa(i)
+
+		if name, _ := scope["name"].(string); name != "" {
+			if first, ok := defined[name]; ok {
+				problems = append(problems, Problem{
+					Pointer: pointer,
+					Message: fmt.Sprintf("duplicate scope %q, already defined at %s", name, first),
+				})
+			} else {
+				defined[name] = pointer
+			}
+		}
+
+		patterns, _ := scope["paths"].([]interface{})
+		for j, pattern := range patterns {
+			pattern, _ := pattern.(string)
+			if err := validateGlob(pattern); err != nil {
+				problems = append(problems, Problem{
+					Pointer: pointer + "/paths/" + strconv.Itoa(j),
+					Message: fmt.Sprintf("malformed path pattern %q: %v", pattern, err),
+				})
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateGlob checks the syntax of a path pattern matched by matchGlob.
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
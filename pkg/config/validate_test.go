@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []Problem
+	}{
+		{
+			name: "valid",
+			config: `{"version": 1, "commit_types": ["feat", {"name": "fix", "aliases": ["bugfix"], "bump": "patch"}],
+				"skip_ci_types": ["feat"], "scopes": [{"name": "api", "paths": ["pkg/api/**"]}],
+				"scope_modes": {"fix": "required"}, "rules": {"header-max-length": "warn"}, "$schema": "schema.json"}`,
+		},
+		{
+			name:   "unknown key",
+			config: `{"commit_types": ["feat"], "commit_type": ["fix"]}`,
+			want:   []Problem{{Pointer: "/commit_type", Message: `unknown key "commit_type"`}},
+		},
+		{
+			name:   "wrong types",
+			config: `{"version": 1.5, "commit_types": "feat", "enforce_scopes": "yes"}`,
+			want: []Problem{
+				{Pointer: "/commit_types", Message: "must be of type array"},
+				{Pointer: "/enforce_scopes", Message: "must be of type boolean"},
+				{Pointer: "/version", Message: "must be of type integer"},
+			},
+		},
+		{
+			name:   "no commit types",
+			config: `{"commit_types": []}`,
+			want:   []Problem{{Pointer: "/commit_types", Message: "must have at least 1 item(s)"}},
+		},
+		{
+			name:   "commit types in both forms",
+			config: `{"commit_types": ["", 1, {"description": "New features"}, {"name": "fix", "bump": "huge", "colour": "red"}]}`,
+			want: []Problem{
+				{Pointer: "/commit_types/0", Message: "must not be empty"},
+				{Pointer: "/commit_types/1", Message: "must be a string or an object"},
+				{Pointer: "/commit_types/2", Message: `missing required key "name"`},
+				{Pointer: "/commit_types/3/bump", Message: `must be one of [major, minor, patch, none], got "huge"`},
+				{Pointer: "/commit_types/3/colour", Message: `unknown key "colour"`},
+			},
+		},
+		{
+			name:   "scope modes",
+			config: `{"commit_types": ["feat"], "scope_modes": {"feat": "always", "a/b~c": "required"}}`,
+			want: []Problem{
+				{Pointer: "/scope_modes/feat", Message: `must be one of [required, optional, forbidden], got "always"`},
+				{Pointer: "/scope_modes/a~1b~0c", Message: `"a/b~c" is not a configured commit type`},
+			},
+		},
+		{
+			name:   "rules in both forms",
+			config: `{"commit_types": ["feat"], "rules": {"a": "on", "b": {"level": "error", "options": {"max": 1}}, "c": 1}}`,
+			want: []Problem{
+				{Pointer: "/rules/a", Message: `must be one of [off, warn, error], got "on"`},
+				{Pointer: "/rules/c", Message: "must be a string or an object"},
+			},
+		},
+		{
+			name: "duplicates and unknown commit types",
+			config: `{"commit_types": ["feat", {"name": "fix", "aliases": ["feat"]}, "fix"], "skip_ci_types": ["docs"],
+				"scope_modes": {"docs": "forbidden"}}`,
+			want: []Problem{
+				{Pointer: "/commit_types/1/aliases/0", Message: `duplicate commit type alias "feat", already defined at /commit_types/0`},
+				{Pointer: "/commit_types/2", Message: `duplicate commit type "fix", already defined at /commit_types/1`},
+				{Pointer: "/skip_ci_types/0", Message: `"docs" is not a configured commit type`},
+				{Pointer: "/scope_modes/docs", Message: `"docs" is not a configured commit type`},
+			},
+		},
+		{
+			name:   "scopes",
+			config: `{"commit_types": ["feat"], "scopes": [{"name": "api", "paths": ["pkg/[api/**"]}, {"name": "api"}, {"paths": []}]}`,
+			want: []Problem{
+				{Pointer: "/scopes/2", Message: `missing required key "name"`},
+				{Pointer: "/scopes/0/paths/0", Message: `malformed path pattern "pkg/[api/**": syntax error in pattern`},
+				{Pointer: "/scopes/1", Message: `duplicate scope "api", already defined at /scopes/0`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.config), &raw); err != nil {
+				t.Fatal(err)
+			}
+
+			got := Validate(raw)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProblemKey(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{pointer: "/commit_types/2/bump", want: "commit_types"},
+		{pointer: "/version", want: "version"},
+		{pointer: "/a~1b~0c/d", want: "a/b~c"},
+		{pointer: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			if got := (Problem{Pointer: tt.pointer}).Key(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := t.TempDir() + "/commitsense.config.json"
+	writeTestFile(t, path, `{"commit_types": ["feat"]}`)
+
+	raw, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := map[string]interface{}{"version": 1.0, "commit_types": []interface{}{"feat"}, "skip_ci_types": []interface{}{"docs"}}
+	if !reflect.DeepEqual(raw, want) {
+		t.Errorf("got %v, want %v", raw, want)
+	}
+	if problems := Validate(raw); len(problems) != 1 || problems[0].Pointer != "/skip_ci_types/0" {
+		t.Errorf("got problems %v, want the default skip ci type that is not configured", problems)
+	}
+}
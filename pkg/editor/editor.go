@@ -0,0 +1,86 @@
+/*
+Package editor provides functionality for editing text in the editor of the user.
+
+The editor is resolved the way Git resolves it, so that CommitSense opens the same editor as git commit.
+
+Usage:
+  - Call the Edit function with the text to edit to get the text the user saved.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package editor
+
+import (
+	"commitsense/pkg/git"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// fileName is the name of the temporary file edited, the same as the one of git commit so that
+// editors recognize it as a commit message.
+const fileName = "COMMIT_EDITMSG"
+
+// defaultEditor is used when no editor is configured, as in Git.
+const defaultEditor = "vi"
+
+// Command returns the editor of the user. It is the editor Git uses, resolved from $GIT_EDITOR, the
+// core.editor configuration of the repository, $VISUAL and $EDITOR in that order.
+func Command() string {
+	if editor := strings.TrimSpace(os.Getenv("GIT_EDITOR")); editor != "" {
+		return editor
+	}
+
+	if editor, err := git.Current().Config("core.editor"); err == nil && strings.TrimSpace(editor) != "" {
+		return strings.TrimSpace(editor)
+	}
+
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+
+	return defaultEditor
+}
+
+// Edit opens the text in the editor of the user and returns the text the user saved.
+func Edit(text string) (string, error) {
+	dir, err := os.MkdirTemp("", "commitsense-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, fileName)
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		return "", err
+	}
+
+	editCmd := command(Command(), path)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+
+	if err := editCmd.Run(); err != nil {
+		return "", err
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(edited), nil
+}
+
+// command returns the command opening the file in the editor. Editors with arguments, such as
+// "code --wait", are run through the shell like Git does.
+func command(editor string, path string) *exec.Cmd {
+	if strings.ContainsAny(editor, " \t'\"$\\|&;<>()") {
+		return exec.Command("sh", "-c", editor+` "$@"`, editor, path) //nolint:gosec // the editor is configured by the user
+	}
+
+	return exec.Command(editor, path) //nolint:gosec // the editor is configured by the user
+}
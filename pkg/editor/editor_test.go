@@ -0,0 +1,56 @@
+package editor
+
+import (
+	"commitsense/pkg/git"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		settings map[string]string
+		want     string
+	}{
+		{
+			name: "default",
+			want: "vi",
+		},
+		{
+			name:     "GIT_EDITOR",
+			env:      map[string]string{"GIT_EDITOR": "nano", "VISUAL": "emacs", "EDITOR": "ed"},
+			settings: map[string]string{"core.editor": "code --wait"},
+			want:     "nano",
+		},
+		{
+			name:     "core.editor",
+			env:      map[string]string{"VISUAL": "emacs", "EDITOR": "ed"},
+			settings: map[string]string{"core.editor": "code --wait"},
+			want:     "code --wait",
+		},
+		{
+			name: "VISUAL",
+			env:  map[string]string{"VISUAL": "emacs", "EDITOR": "ed"},
+			want: "emacs",
+		},
+		{
+			name: "EDITOR",
+			env:  map[string]string{"EDITOR": "ed"},
+			want: "ed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"GIT_EDITOR", "VISUAL", "EDITOR"} {
+				t.Setenv(name, tt.env[name])
+			}
+			git.Use(&git.MemoryRepository{Dir: t.TempDir(), Settings: tt.settings})
+			t.Cleanup(func() { git.Use(nil) })
+
+			if got := Command(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Package git provides access to the Git repository CommitSense operates on.
+
+This file contains the implementation of the repository running the git binary. The arguments are passed to git
+as they are, without a shell, so paths with spaces or special characters need no quoting.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	logFieldSeparator  = "\x00"
+	logRecordSeparator = "\x1e"
+)
+
+// CommandRepository is a Repository accessed by running the git binary.
+type CommandRepository struct {
+	// Dir is the directory git is run in, the working directory when empty.
+	Dir string
+}
+
+// Root returns the top-level directory of the working tree.
+func (r *CommandRepository) Root() (string, error) {
+	output, err := r.output("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("could not find the repository root: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Status returns the files with changes in the working tree or in the index, untracked files included.
+func (r *CommandRepository) Status() ([]FileStatus, error) {
+	output, err := r.output("status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("could not get the changed files: %w", err)
+	}
+	return parseStatus(output), nil
+}
+
+// StagedFiles returns the files with changes staged for the next commit.
+func (r *CommandRepository) StagedFiles() ([]StagedFile, error) {
+	status, err := r.Status()
+	if err != nil {
+		return nil, err
+	}
+	return stagedFiles(status)
+}
+
+// Stage adds the current content of the files, including deletions, to the index.
+func (r *CommandRepository) Stage(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return r.run(append([]string{"add", "--all", "--"}, topLevelPathspecs(paths)...)...)
+}
+
+// Unstage removes the staged changes of the files from the index, leaving the working tree untouched.
+func (r *CommandRepository) Unstage(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return r.run(append([]string{"reset", "--quiet", "--"}, topLevelPathspecs(paths)...)...)
+}
+
+// Diff returns the staged or the unstaged changes of the file at the path, or of all the files when the path is
+// empty, as a unified diff.
+func (r *CommandRepository) Diff(cached bool, path string) (string, error) {
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	if cached {
+		args = append(args, "--cached")
+	}
+	args = append(args, "--")
+	if path != "" {
+		args = append(args, topLevelPathspec(path))
+	}
+
+	output, err := r.output(args...)
+	if err != nil {
+		return "", fmt.Errorf("could not get the changes: %w", err)
+	}
+	return string(output), nil
+}
+
+// ApplyToIndex applies the patch to the index with `git apply --cached`, run in the root of the repository as
+// the paths of the patch are relative to it.
+func (r *CommandRepository) ApplyToIndex(patch string) error {
+	root, err := r.Root()
+	if err != nil {
+		return err
+	}
+
+	applyCmd := exec.Command("git", "apply", "--cached", "-")
+	applyCmd.Dir = root
+	applyCmd.Stdin = strings.NewReader(patch)
+	applyCmd.Stdout = os.Stdout
+	applyCmd.Stderr = os.Stderr
+
+	return applyCmd.Run()
+}
+
+// Commit creates a commit with the message from the index, or from only the files at the paths,
+// running the hooks of the repository.
+func (r *CommandRepository) Commit(message string, only []string) error {
+	return r.run(CommitCommand(message, only)[1:]...)
+}
+
+// Log returns the commits in the revision range, newest first, leaving out merge commits.
+func (r *CommandRepository) Log(revisionRange string) ([]LogEntry, error) {
+	output, err := r.output("log", "--no-merges", "--format=%H%x00%an <%ae>%x00%B%x1e", revisionRange, "--")
+	if err != nil {
+		return nil, fmt.Errorf("could not read the git history for %q: %w", revisionRange, err)
+	}
+	return parseLog(output), nil
+}
+
+// Authors returns the distinct authors of the history of HEAD as "Name <email>", sorted.
+func (r *CommandRepository) Authors() ([]string, error) {
+	output, err := r.output("log", "--format=%an <%ae>")
+	if err != nil {
+		return nil, fmt.Errorf("could not read the authors: %w", err)
+	}
+	authors := strings.TrimSpace(string(output))
+	if authors == "" {
+		return nil, nil
+	}
+	return sortedUnique(strings.Split(authors, "\n")), nil
+}
+
+// Tags returns the tags reachable from HEAD, the most recently created first.
+func (r *CommandRepository) Tags() ([]string, error) {
+	output, err := r.output("tag", "--merged", "HEAD", "--sort=-creatordate")
+	if err != nil {
+		return nil, fmt.Errorf("could not read the git tags: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// CreateTag creates an annotated tag pointing to HEAD.
+func (r *CommandRepository) CreateTag(name string, message string) error {
+	return r.run("tag", "--annotate", name, "--message", message)
+}
+
+// Config returns the value of the configuration key, or an empty string when it is not set.
+func (r *CommandRepository) Config(key string) (string, error) {
+	output, err := r.output("config", "--get", key)
+
+	// git config exits with 1 when the key is not set.
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read %s from the git configuration: %w", key, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HooksDir returns the directory Git runs the hooks of the repository from.
+// The core.hooksPath setting is respected, relative paths being resolved from the repository root.
+func (r *CommandRepository) HooksDir() (string, error) {
+	if hooksPath, err := r.Config("core.hooksPath"); err == nil && hooksPath != "" {
+		return resolveHooksPath(r, hooksPath)
+	}
+
+	output, err := r.output("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", errors.New("could not find the hooks directory, is this a git repository?")
+	}
+
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.Dir, dir)
+	}
+	return filepath.Abs(dir)
+}
+
+// output runs git with the arguments and returns its output.
+func (r *CommandRepository) output(args ...string) ([]byte, error) {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = r.Dir
+
+	output, err := gitCmd.Output()
+	if err != nil {
+		return nil, CommandError(err)
+	}
+	return output, nil
+}
+
+// run runs git with the arguments, forwarding its output to the terminal.
+func (r *CommandRepository) run(args ...string) error {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = r.Dir
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+
+	return gitCmd.Run()
+}
+
+// CommandError adds the output git wrote to stderr to the error returned by os/exec.
+func CommandError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// parseStatus parses the output of `git status --porcelain=v2 -z`. The entries are separated by NUL characters,
+// and the paths are neither quoted nor escaped. The entries of renamed and copied files are followed by the
+// original path:
+//
+//	1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+//	2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <score> <path>\x00<origPath>
+//	u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+//	? <path>
+func parseStatus(output []byte) []FileStatus {
+	var files []FileStatus
+
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if entry == "" {
+			continue
+		}
+
+		// The number of fields of the entry, the path being the last one.
+		var numFields int
+		switch entry[0] {
+		case '1':
+			numFields = 9
+		case '2':
+			numFields = 10
+		case 'u':
+			numFields = 11
+		case '?':
+			files = append(files, FileStatus{Path: entry[2:], Index: '?', Worktree: '?'})
+			continue
+		default:
+			continue
+		}
+
+		fields := strings.SplitN(entry, " ", numFields)
+		if len(fields) != numFields || len(fields[1]) != 2 {
+			continue
+		}
+
+		file := FileStatus{
+			Path:        fields[numFields-1],
+			Index:       statusCode(fields[1][0]),
+			Worktree:    statusCode(fields[1][1]),
+			IsSubmodule: strings.HasPrefix(fields[2], "S"),
+		}
+		if entry[0] == '2' && i+1 < len(entries) {
+			i++
+			file.OldPath = entries[i]
+		}
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// statusCode converts a status code of the porcelain v2 format, where unmodified is '.', to a StatusCode.
+func statusCode(code byte) StatusCode {
+	if code == '.' {
+		return ' '
+	}
+	return StatusCode(code)
+}
+
+// parseLog parses the output of git log formatted with the SHA, the author and the message of the commits
+// separated by logFieldSeparator and the commits separated by logRecordSeparator.
+func parseLog(output []byte) []LogEntry {
+	var entries []LogEntry
+	for _, record := range strings.Split(string(output), logRecordSeparator) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, logFieldSeparator, 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		entries = append(entries, LogEntry{SHA: fields[0], Author: fields[1], Message: strings.TrimSpace(fields[2])})
+	}
+
+	return entries
+}
+
+// topLevelPathspec returns a pathspec matching exactly the path relative to the repository root,
+// as reported by git status, regardless of the current directory.
+func topLevelPathspec(path string) string {
+	return ":(top,literal)" + path
+}
+
+func topLevelPathspecs(paths []string) []string {
+	pathspecs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		pathspecs = append(pathspecs, topLevelPathspec(path))
+	}
+	return pathspecs
+}
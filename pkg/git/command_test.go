@@ -0,0 +1,289 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []FileStatus
+	}{
+		{
+			name:   "empty",
+			output: "",
+		},
+		{
+			name: "ordinary changes",
+			output: "1 M. N... 100644 100644 100644 1111111111111111111111111111111111111111 2222222222222222222222222222222222222222 staged.go\x00" +
+				"1 .M N... 100644 100644 100644 1111111111111111111111111111111111111111 1111111111111111111111111111111111111111 unstaged.go\x00" +
+				"1 A. N... 000000 100644 100644 0000000000000000000000000000000000000000 3333333333333333333333333333333333333333 added.go\x00" +
+				"1 .D N... 100644 100644 000000 4444444444444444444444444444444444444444 4444444444444444444444444444444444444444 deleted.go\x00" +
+				"1 T. N... 100644 120000 120000 5555555555555555555555555555555555555555 6666666666666666666666666666666666666666 link\x00",
+			want: []FileStatus{
+				{Path: "staged.go", Index: 'M', Worktree: ' '},
+				{Path: "unstaged.go", Index: ' ', Worktree: 'M'},
+				{Path: "added.go", Index: 'A', Worktree: ' '},
+				{Path: "deleted.go", Index: ' ', Worktree: 'D'},
+				{Path: "link", Index: 'T', Worktree: ' '},
+			},
+		},
+		{
+			name: "paths with spaces and non-ASCII characters",
+			output: "1 M. N... 100644 100644 100644 1111111111111111111111111111111111111111 2222222222222222222222222222222222222222 dir/with space.go\x00" +
+				"? päivä.txt\x00",
+			want: []FileStatus{
+				{Path: "dir/with space.go", Index: 'M', Worktree: ' '},
+				{Path: "päivä.txt", Index: '?', Worktree: '?'},
+			},
+		},
+		{
+			name: "rename followed by the original path",
+			output: "2 R. N... 100644 100644 100644 1111111111111111111111111111111111111111 1111111111111111111111111111111111111111 R100 new name.go\x00old name.go\x00" +
+				"1 .M N... 100644 100644 100644 1111111111111111111111111111111111111111 1111111111111111111111111111111111111111 after.go\x00",
+			want: []FileStatus{
+				{Path: "new name.go", OldPath: "old name.go", Index: 'R', Worktree: ' '},
+				{Path: "after.go", Index: ' ', Worktree: 'M'},
+			},
+		},
+		{
+			name:   "unmerged",
+			output: "u UU N... 100644 100644 100644 100644 1111111111111111111111111111111111111111 2222222222222222222222222222222222222222 3333333333333333333333333333333333333333 conflict.go\x00",
+			want:   []FileStatus{{Path: "conflict.go", Index: 'U', Worktree: 'U'}},
+		},
+		{
+			name:   "submodule",
+			output: "1 .M SC.. 160000 160000 160000 1111111111111111111111111111111111111111 1111111111111111111111111111111111111111 vendor/lib\x00",
+			want:   []FileStatus{{Path: "vendor/lib", Index: ' ', Worktree: 'M', IsSubmodule: true}},
+		},
+		{
+			name:   "ignored entries and headers are skipped",
+			output: "# branch.oid 1111111111111111111111111111111111111111\x00! ignored.log\x00? new.go\x00",
+			want:   []FileStatus{{Path: "new.go", Index: '?', Worktree: '?'}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseStatus([]byte(tt.output))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []LogEntry
+	}{
+		{
+			name:   "empty",
+			output: "",
+		},
+		{
+			name: "multiple commits",
+			output: "aaaa\x00Jane Doe <jane@example.com>\x00feat: Add pages\n\nThe body.\n\x1e\n" +
+				"bbbb\x00John Doe <john@example.com>\x00fix: Handle errors\n\x1e\n",
+			want: []LogEntry{
+				{SHA: "aaaa", Author: "Jane Doe <jane@example.com>", Message: "feat: Add pages\n\nThe body."},
+				{SHA: "bbbb", Author: "John Doe <john@example.com>", Message: "fix: Handle errors"},
+			},
+		},
+		{
+			name:   "message containing the field separator",
+			output: "aaaa\x00Jane Doe <jane@example.com>\x00feat: Add\x00pages\n\x1e\n",
+			want:   []LogEntry{{SHA: "aaaa", Author: "Jane Doe <jane@example.com>", Message: "feat: Add\x00pages"}},
+		},
+		{
+			name:   "malformed record",
+			output: "aaaa\x00no message\x1e\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLog([]byte(tt.output))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newCommandRepository creates a repository with the git binary in a temporary directory, with an initial
+// commit of the files. The test is skipped when git is not installed.
+func newCommandRepository(t *testing.T, files map[string]string) *CommandRepository {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(dir, ".gitconfig-global"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	r := &CommandRepository{Dir: dir}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "Jane Doe"},
+		{"config", "user.email", "jane@example.com"},
+	} {
+		if _, err := r.output(args...); err != nil {
+			t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path, content := range files {
+		writeFile(t, dir, path, content)
+		paths = append(paths, path)
+	}
+	if _, err := r.output(append([]string{"add", "--"}, paths...)...); err != nil {
+		t.Fatalf("staging the files: %v", err)
+	}
+	if _, err := r.output("commit", "--quiet", "-m", "chore: Initial commit"); err != nil {
+		t.Fatalf("committing: %v", err)
+	}
+
+	return r
+}
+
+func TestCommandRepository(t *testing.T) {
+	r := newCommandRepository(t, map[string]string{"a.go": "package a\n", "b.go": "package b\n"})
+	testRepository(t, r, r.Dir)
+
+	hooksDir, err := r.HooksDir()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if want := filepath.Join(r.Dir, ".git", "hooks"); hooksDir != want {
+		t.Errorf("got hooks directory %q, want %q", hooksDir, want)
+	}
+}
+
+func TestCommandRepositoryDiffAndApplyToIndex(t *testing.T) {
+	r := newCommandRepository(t, map[string]string{"file.txt": "one\ntwo\n"})
+	writeFile(t, r.Dir, "file.txt", "one\nTWO\n")
+
+	diff, err := r.Diff(false, "file.txt")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if !strings.Contains(diff, "+TWO\n") {
+		t.Fatalf("got diff\n%s\nwant the change of file.txt", diff)
+	}
+
+	if err := r.ApplyToIndex(diff); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if staged, err := r.Diff(true, ""); err != nil || staged != diff {
+		t.Errorf("got staged changes\n%s\n(error %v), want\n%s", staged, err, diff)
+	}
+	if unstaged, err := r.Diff(false, ""); err != nil || unstaged != "" {
+		t.Errorf("got unstaged changes\n%s\n(error %v), want none", unstaged, err)
+	}
+}
+
+// testRepository tests the operations of a repository created with the files a.go and b.go in dir.
+func testRepository(t *testing.T, r Repository, dir string) {
+	t.Helper()
+
+	root, err := r.Root()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if want, _ := filepath.EvalSymlinks(dir); root != want && root != dir {
+		t.Errorf("got root %q, want %q", root, dir)
+	}
+
+	writeFile(t, dir, "a.go", "package a // changed\n")
+	writeFile(t, dir, "new.go", "package c\n")
+	if err := os.Remove(filepath.Join(dir, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := r.Status()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	wantStatus := []FileStatus{
+		{Path: "a.go", Index: ' ', Worktree: 'M'},
+		{Path: "b.go", Index: ' ', Worktree: 'D'},
+		{Path: "new.go", Index: '?', Worktree: '?'},
+	}
+	if !reflect.DeepEqual(status, wantStatus) {
+		t.Errorf("got status %+v, want %+v", status, wantStatus)
+	}
+
+	if err := r.Stage([]string{"a.go", "new.go"}); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if err := r.Unstage([]string{"new.go"}); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	staged, err := r.StagedFiles()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if want := []StagedFile{{Status: 'M', Path: "a.go"}}; !reflect.DeepEqual(staged, want) {
+		t.Errorf("got staged files %+v, want %+v", staged, want)
+	}
+
+	if err := r.Commit("feat: Change a\n\nThe body.", []string{"a.go"}); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if status, _ := r.Status(); !reflect.DeepEqual(status, wantStatus[1:]) {
+		t.Errorf("got status %+v after committing a.go, want %+v", status, wantStatus[1:])
+	}
+
+	if err := r.Stage([]string{"b.go"}); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if staged, _ := r.StagedFiles(); !reflect.DeepEqual(staged, []StagedFile{{Status: 'D', Path: "b.go"}}) {
+		t.Errorf("got staged files %+v, want the deletion of b.go", staged)
+	}
+
+	if err := r.CreateTag("v1.0.0", "Release v1.0.0"); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if err := r.Commit("fix: Remove b", nil); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	log, err := r.Log("v1.0.0..HEAD")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if len(log) != 1 || log[0].Message != "fix: Remove b" || log[0].Author != "Jane Doe <jane@example.com>" {
+		t.Errorf("got log %+v, want the commit after v1.0.0", log)
+	}
+	if log, _ := r.Log("HEAD"); len(log) != 3 || log[1].Message != "feat: Change a\n\nThe body." {
+		t.Errorf("got log %+v, want the 3 commits of HEAD", log)
+	}
+
+	if tags, err := r.Tags(); err != nil || !reflect.DeepEqual(tags, []string{"v1.0.0"}) {
+		t.Errorf("got tags %v (error %v), want [v1.0.0]", tags, err)
+	}
+	if authors, err := r.Authors(); err != nil || !reflect.DeepEqual(authors, []string{"Jane Doe <jane@example.com>"}) {
+		t.Errorf("got authors %v (error %v), want [Jane Doe <jane@example.com>]", authors, err)
+	}
+
+	if name, err := r.Config("user.name"); err != nil || name != "Jane Doe" {
+		t.Errorf("got user.name %q (error %v), want %q", name, err, "Jane Doe")
+	}
+	if value, err := r.Config("commitsense.unset"); err != nil || value != "" {
+		t.Errorf("got %q (error %v) for an unset key, want an empty value", value, err)
+	}
+}
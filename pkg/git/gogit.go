@@ -0,0 +1,510 @@
+/*
+Package git provides access to the Git repository CommitSense operates on.
+
+This file contains the implementation of the repository using go-git, for environments where the git binary is
+not installed. Unlike the git binary, go-git does not run the hooks of the repository when committing.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package git
+
+import (
+	"commitsense/pkg/hunk"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// GoGitRepository is a Repository accessed with go-git, without running the git binary.
+type GoGitRepository struct {
+	// Dir is a directory inside the working tree, the working directory when empty.
+	Dir string
+}
+
+// Root returns the top-level directory of the working tree.
+func (r *GoGitRepository) Root() (string, error) {
+	_, worktree, err := r.open()
+	if err != nil {
+		return "", fmt.Errorf("could not find the repository root: %w", err)
+	}
+	return worktree.Filesystem.Root(), nil
+}
+
+// Status returns the files with changes in the working tree or in the index, untracked files included.
+func (r *GoGitRepository) Status() ([]FileStatus, error) {
+	repo, worktree, err := r.open()
+	if err != nil {
+		return nil, fmt.Errorf("could not get the changed files: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("could not get the changed files: %w", err)
+	}
+
+	index, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the index: %w", err)
+	}
+	submodules := map[string]bool{}
+	for _, entry := range index.Entries {
+		if entry.Mode == filemode.Submodule {
+			submodules[entry.Name] = true
+		}
+	}
+
+	files := make([]FileStatus, 0, len(status))
+	for path, file := range status {
+		if file.Staging == gogit.Unmodified && file.Worktree == gogit.Unmodified {
+			continue
+		}
+		files = append(files, FileStatus{
+			Path:        path,
+			OldPath:     file.Extra,
+			Index:       StatusCode(file.Staging),
+			Worktree:    StatusCode(file.Worktree),
+			IsSubmodule: submodules[path],
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return files, nil
+}
+
+// StagedFiles returns the files with changes staged for the next commit.
+func (r *GoGitRepository) StagedFiles() ([]StagedFile, error) {
+	status, err := r.Status()
+	if err != nil {
+		return nil, err
+	}
+	return stagedFiles(status)
+}
+
+// Stage adds the current content of the files, including deletions, to the index.
+func (r *GoGitRepository) Stage(paths []string) error {
+	_, worktree, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if _, err := worktree.Add(path); err != nil {
+			return fmt.Errorf("could not stage %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// Unstage removes the staged changes of the files from the index, leaving the working tree untouched.
+func (r *GoGitRepository) Unstage(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	_, worktree, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	return worktree.Restore(&gogit.RestoreOptions{Staged: true, Files: paths})
+}
+
+// Diff returns the staged or the unstaged changes of the file at the path, or of all the files when the path is
+// empty, as a unified diff. Unlike git, renames are shown as a deletion and an addition, and submodules are
+// left out.
+func (r *GoGitRepository) Diff(cached bool, path string) (string, error) {
+	diff, err := r.diff(cached, path)
+	if err != nil {
+		return "", fmt.Errorf("could not get the changes: %w", err)
+	}
+	return diff, nil
+}
+
+func (r *GoGitRepository) diff(cached bool, path string) (string, error) {
+	repo, worktree, err := r.open()
+	if err != nil {
+		return "", err
+	}
+
+	status, err := r.Status()
+	if err != nil {
+		return "", err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", err
+	}
+
+	var head *object.Tree
+	if cached {
+		if head, err = headTree(repo); err != nil {
+			return "", err
+		}
+	}
+
+	var files patch
+	for _, file := range status {
+		if path != "" && file.Path != path || file.IsSubmodule {
+			continue
+		}
+
+		if cached && !file.IsStaged() || !cached && (file.Worktree == ' ' || file.Worktree == '?') {
+			continue
+		}
+
+		from, to, err := diffFiles(repo, idx, head, worktree, file, cached)
+		if err != nil {
+			return "", err
+		}
+
+		if filePatch := newFilePatch(from, to); filePatch != nil {
+			files = append(files, filePatch)
+		}
+	}
+
+	var diff strings.Builder
+	if err := fdiff.NewUnifiedEncoder(&diff, fdiff.DefaultContextLines).Encode(files); err != nil {
+		return "", err
+	}
+	return diff.String(), nil
+}
+
+// ApplyToIndex applies the patch to the index without touching the working tree. The hunks must apply at the
+// lines they start from, as in the patches built from Diff, and binary patches are not supported.
+func (r *GoGitRepository) ApplyToIndex(patch string) error {
+	files, err := hunk.Parse(patch)
+	if err != nil {
+		return fmt.Errorf("could not parse the patch: %w", err)
+	}
+
+	repo, _, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("could not read the index: %w", err)
+	}
+
+	// The index is only written once every file of the patch applies.
+	for _, file := range files {
+		if err := applyToIndex(repo, idx, file); err != nil {
+			return fmt.Errorf("could not apply the patch to %s: %w", file.NewPath, err)
+		}
+	}
+
+	return repo.Storer.SetIndex(idx)
+}
+
+// Commit creates a commit with the message from the index, or from only the files at the paths.
+// The author is read from the Git configuration, and the hooks of the repository are not run.
+// go-git always commits the whole index, so committing only some files fails when other files are staged.
+func (r *GoGitRepository) Commit(message string, only []string) error {
+	staged, err := r.StagedFiles()
+	if err != nil {
+		return err
+	}
+	if len(only) > 0 {
+		for _, path := range Paths(staged) {
+			if !contains(only, path) {
+				return fmt.Errorf("%s is staged, committing only some files needs the git binary when other files are staged", path)
+			}
+		}
+
+		if err := r.Stage(only); err != nil {
+			return err
+		}
+	}
+
+	_, worktree, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	if _, err := worktree.Commit(message, &gogit.CommitOptions{}); err != nil {
+		return fmt.Errorf("could not create the commit: %w", err)
+	}
+
+	return nil
+}
+
+// Log returns the commits in the revision range, newest first, leaving out merge commits.
+// A range is either a single revision or two revisions separated by "..".
+func (r *GoGitRepository) Log(revisionRange string) ([]LogEntry, error) {
+	entries, err := r.log(revisionRange)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the git history for %q: %w", revisionRange, err)
+	}
+	return entries, nil
+}
+
+func (r *GoGitRepository) log(revisionRange string) ([]LogEntry, error) {
+	if strings.Contains(revisionRange, "...") {
+		return nil, errors.New("symmetric difference ranges are not supported without the git binary")
+	}
+
+	repo, _, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, isRange := strings.Cut(revisionRange, "..")
+	if !isRange {
+		from, to = "", revisionRange
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+
+	excluded := map[plumbing.Hash]bool{}
+	if from != "" {
+		if excluded, err = ancestors(repo, from); err != nil {
+			return nil, err
+		}
+	}
+
+	toHash, err := resolve(repo, to)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := repo.Log(&gogit.LogOptions{From: toHash, Order: gogit.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	err = commits.ForEach(func(c *object.Commit) error {
+		if !excluded[c.Hash] && c.NumParents() <= 1 {
+			entries = append(entries, LogEntry{
+				SHA:     c.Hash.String(),
+				Author:  c.Author.Name + " <" + c.Author.Email + ">",
+				Message: strings.TrimSpace(c.Message),
+			})
+		}
+		return nil
+	})
+
+	return entries, err
+}
+
+// Authors returns the distinct authors of the history of HEAD as "Name <email>", sorted.
+func (r *GoGitRepository) Authors() ([]string, error) {
+	repo, _, err := r.open()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the authors: %w", err)
+	}
+
+	commits, err := repo.Log(&gogit.LogOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read the authors: %w", err)
+	}
+
+	var authors []string
+	err = commits.ForEach(func(c *object.Commit) error {
+		authors = append(authors, c.Author.Name+" <"+c.Author.Email+">")
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read the authors: %w", err)
+	}
+
+	return sortedUnique(authors), nil
+}
+
+// Tags returns the tags reachable from HEAD, the most recently created first. The creation date of an
+// annotated tag is the date of the tag, and of a lightweight tag the date of the commit it points to.
+func (r *GoGitRepository) Tags() ([]string, error) {
+	tags, err := r.tags()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the git tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *GoGitRepository) tags() ([]string, error) {
+	repo, _, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+
+	reachable, err := ancestors(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	type tag struct {
+		name    string
+		created time.Time
+	}
+	var tags []tag
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		var (
+			target  *object.Commit
+			created time.Time
+		)
+		if annotated, err := repo.TagObject(ref.Hash()); err == nil {
+			if target, err = annotated.Commit(); err != nil {
+				// Tags of other objects than commits are never reachable from HEAD.
+				return nil
+			}
+			created = annotated.Tagger.When
+		} else {
+			if target, err = repo.CommitObject(ref.Hash()); err != nil {
+				return nil
+			}
+			created = target.Committer.When
+		}
+
+		if reachable[target.Hash] {
+			tags = append(tags, tag{name: ref.Name().Short(), created: created})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].created.After(tags[j].created) })
+
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.name)
+	}
+	return names, nil
+}
+
+// CreateTag creates an annotated tag pointing to HEAD, tagged by the user of the Git configuration.
+func (r *GoGitRepository) CreateTag(name string, message string) error {
+	repo, _, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("could not resolve HEAD: %w", err)
+	}
+
+	if _, err := repo.CreateTag(name, head.Hash(), &gogit.CreateTagOptions{Message: message}); err != nil {
+		return fmt.Errorf("could not create the tag %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Config returns the value of the configuration key, or an empty string when it is not set.
+// The system, global and repository configurations are read, the repository one taking precedence.
+func (r *GoGitRepository) Config(key string) (string, error) {
+	repo, _, err := r.open()
+	if err != nil {
+		return "", fmt.Errorf("could not read %s from the git configuration: %w", key, err)
+	}
+
+	cfg, err := repo.ConfigScoped(gogitconfig.SystemScope)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s from the git configuration: %w", key, err)
+	}
+
+	// The key is "section.option" or "section.subsection.option", the subsection possibly containing dots.
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first < 0 {
+		return "", fmt.Errorf("invalid git configuration key %q", key)
+	}
+
+	section := cfg.Raw.Section(key[:first])
+	if first == last {
+		return section.Option(key[last+1:]), nil
+	}
+	return section.Subsection(key[first+1 : last]).Option(key[last+1:]), nil
+}
+
+// HooksDir returns the directory Git runs the hooks of the repository from.
+// The core.hooksPath setting is respected, relative paths being resolved from the repository root.
+func (r *GoGitRepository) HooksDir() (string, error) {
+	if hooksPath, err := r.Config("core.hooksPath"); err == nil && hooksPath != "" {
+		return resolveHooksPath(r, hooksPath)
+	}
+
+	repo, _, err := r.open()
+	if err != nil {
+		return "", errors.New("could not find the hooks directory, is this a git repository?")
+	}
+
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", errors.New("could not find the hooks directory, the repository is not stored on disk")
+	}
+
+	return filepath.Join(storage.Filesystem().Root(), "hooks"), nil
+}
+
+// open opens the repository containing the directory, along with its working tree.
+func (r *GoGitRepository) open() (*gogit.Repository, *gogit.Worktree, error) {
+	dir := r.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return repo, worktree, nil
+}
+
+// resolve returns the commit the revision points to.
+func resolve(repo *gogit.Repository, revision string) (plumbing.Hash, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("unknown revision %q: %w", revision, err)
+	}
+	return *hash, nil
+}
+
+// ancestors returns the commit the revision points to and all of its ancestors.
+func ancestors(repo *gogit.Repository, revision string) (map[plumbing.Hash]bool, error) {
+	hash, err := resolve(repo, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := repo.Log(&gogit.LogOptions{From: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[plumbing.Hash]bool{}
+	err = commits.ForEach(func(c *object.Commit) error {
+		seen[c.Hash] = true
+		return nil
+	})
+
+	return seen, err
+}
@@ -0,0 +1,154 @@
+package git
+
+import (
+	"commitsense/pkg/hunk"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// newGoGitRepository creates a repository in a temporary directory with an initial commit of the files.
+func newGoGitRepository(t *testing.T, files map[string]string) *GoGitRepository {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("initializing the repository: %v", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("reading the configuration: %v", err)
+	}
+	cfg.User.Name = "Jane Doe"
+	cfg.User.Email = "jane@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("writing the configuration: %v", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("opening the working tree: %v", err)
+	}
+	for path, content := range files {
+		writeFile(t, dir, path, content)
+		if _, err := worktree.Add(path); err != nil {
+			t.Fatalf("staging %s: %v", path, err)
+		}
+	}
+
+	author := &object.Signature{Name: "Jane Doe", Email: "jane@example.com", When: time.Now()}
+	if _, err := worktree.Commit("chore: Initial commit", &gogit.CommitOptions{Author: author}); err != nil {
+		t.Fatalf("committing: %v", err)
+	}
+
+	return &GoGitRepository{Dir: dir}
+}
+
+func writeFile(t *testing.T, dir string, path string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func TestGoGitRepository(t *testing.T) {
+	r := newGoGitRepository(t, map[string]string{"a.go": "package a\n", "b.go": "package b\n"})
+	testRepository(t, r, r.Dir)
+
+	hooksDir, err := r.HooksDir()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if want := filepath.Join(r.Dir, ".git", "hooks"); hooksDir != want {
+		t.Errorf("got hooks directory %q, want %q", hooksDir, want)
+	}
+}
+
+func TestGoGitCommitOnlyWithOtherFilesStaged(t *testing.T) {
+	r := newGoGitRepository(t, map[string]string{"a.go": "package a\n", "b.go": "package b\n"})
+	writeFile(t, r.Dir, "a.go", "package a // changed\n")
+	writeFile(t, r.Dir, "b.go", "package b // changed\n")
+	if err := r.Stage([]string{"a.go"}); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if err := r.Commit("fix: Change b", []string{"b.go"}); err == nil {
+		t.Error("committing only b.go with a.go staged succeeded, want an error")
+	}
+	if log, _ := r.Log("HEAD"); len(log) != 1 {
+		t.Errorf("got %d commits, want only the initial commit", len(log))
+	}
+}
+
+func TestGoGitDiffAndApplyToIndex(t *testing.T) {
+	r := newGoGitRepository(t, map[string]string{
+		"numbers.txt": "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		"other.txt":   "other\n",
+	})
+	writeFile(t, r.Dir, "numbers.txt", "1\nTWO\n3\n4\n5\n6\n7\n8\n9\n10\nELEVEN\n12\n")
+
+	diff, err := r.Diff(false, "numbers.txt")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	files, err := hunk.Parse(diff)
+	if err != nil {
+		t.Fatalf("parsing the diff: %v\n%s", err, diff)
+	}
+	if len(files) != 1 || len(files[0].Hunks) != 2 {
+		t.Fatalf("got %d files, want numbers.txt with 2 hunks:\n%s", len(files), diff)
+	}
+
+	if err := r.ApplyToIndex(files[0].Patch(files[0].Hunks[:1])); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	staged, err := r.Diff(true, "")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if !strings.Contains(staged, "+TWO\n") || strings.Contains(staged, "ELEVEN") {
+		t.Errorf("got staged changes\n%s\nwant only the first hunk", staged)
+	}
+
+	unstaged, err := r.Diff(false, "")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if !strings.Contains(unstaged, "+ELEVEN\n") || strings.Contains(unstaged, "TWO") {
+		t.Errorf("got unstaged changes\n%s\nwant only the second hunk", unstaged)
+	}
+
+	status, err := r.Status()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	want := []FileStatus{{Path: "numbers.txt", Index: 'M', Worktree: 'M'}}
+	if len(status) != 1 || status[0] != want[0] {
+		t.Errorf("got status %v, want %v", status, want)
+	}
+}
+
+func TestGoGitApplyToIndexRejectsStalePatch(t *testing.T) {
+	r := newGoGitRepository(t, map[string]string{"file.txt": "one\ntwo\n"})
+	writeFile(t, r.Dir, "file.txt", "one\nTWO\n")
+
+	diff, err := r.Diff(false, "")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if err := r.ApplyToIndex(diff); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if err := r.ApplyToIndex(diff); err == nil {
+		t.Error("applying the patch twice succeeded, want an error")
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Package git provides access to the Git repository CommitSense operates on.
+
+This file contains the in-memory implementation of the repository, for testing the commands in isolation
+without a Git repository on disk.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package git
+
+import (
+	"commitsense/pkg/hunk"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MemoryRepository is a Repository held in memory. The history is linear and only the status codes of the
+// files are tracked, not their contents.
+type MemoryRepository struct {
+	// Dir is the top-level directory of the working tree.
+	Dir string
+	// Files are the files with changes in the working tree or in the index.
+	Files []FileStatus
+	// StagedDiffs and UnstagedDiffs hold the diffs returned by Diff by path.
+	StagedDiffs   map[string]string
+	UnstagedDiffs map[string]string
+	// Patches holds the patches applied to the index, in the order they were applied.
+	Patches []string
+	// History holds the commits of HEAD, newest first.
+	History []LogEntry
+	// TagRefs holds the tags, the most recently created first.
+	TagRefs []MemoryTag
+	// Settings holds the Git configuration by key, e.g. "user.name".
+	Settings map[string]string
+	// Hooks are the hooks run by Commit by name, e.g. "commit-msg". A hook failing aborts the commit.
+	Hooks map[string]func(message string) error
+}
+
+// MemoryTag is a tag of a MemoryRepository.
+type MemoryTag struct {
+	Name    string
+	SHA     string
+	Message string
+}
+
+// Root returns the top-level directory of the working tree.
+func (r *MemoryRepository) Root() (string, error) {
+	return r.Dir, nil
+}
+
+// Status returns the files with changes in the working tree or in the index.
+func (r *MemoryRepository) Status() ([]FileStatus, error) {
+	return append([]FileStatus(nil), r.Files...), nil
+}
+
+// StagedFiles returns the files with changes staged for the next commit.
+func (r *MemoryRepository) StagedFiles() ([]StagedFile, error) {
+	return stagedFiles(r.Files)
+}
+
+// Stage moves the changes of the files in the working tree to the index.
+func (r *MemoryRepository) Stage(paths []string) error {
+	for _, path := range paths {
+		file := r.file(path)
+		if file == nil {
+			return fmt.Errorf("pathspec %q did not match any files", path)
+		}
+		if file.Worktree == ' ' {
+			continue
+		}
+
+		switch {
+		case file.Worktree == '?':
+			file.Index = 'A'
+		case file.Index == ' ':
+			file.Index = file.Worktree
+		}
+		file.Worktree = ' '
+	}
+
+	return nil
+}
+
+// Unstage moves the staged changes of the files back to the working tree.
+func (r *MemoryRepository) Unstage(paths []string) error {
+	for _, path := range paths {
+		file := r.file(path)
+		if file == nil || file.Index == ' ' || file.Index == '?' {
+			continue
+		}
+
+		switch {
+		case file.Index == 'A':
+			file.Index, file.Worktree = '?', '?'
+		case file.Worktree == ' ':
+			file.Index, file.Worktree = ' ', file.Index
+		default:
+			file.Index = ' '
+		}
+	}
+
+	return nil
+}
+
+// Diff returns the staged or the unstaged diff of the file at the path, or the diffs of all the files sorted by
+// path when the path is empty.
+func (r *MemoryRepository) Diff(cached bool, path string) (string, error) {
+	diffs := r.UnstagedDiffs
+	if cached {
+		diffs = r.StagedDiffs
+	}
+
+	if path != "" {
+		return diffs[path], nil
+	}
+
+	paths := make([]string, 0, len(diffs))
+	for p := range diffs {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var diff strings.Builder
+	for _, p := range paths {
+		diff.WriteString(diffs[p])
+	}
+	return diff.String(), nil
+}
+
+// ApplyToIndex records the patch and marks the changes of the files it patches as staged. The changes of the
+// files stay in the working tree too, as the patch may stage only some of them.
+func (r *MemoryRepository) ApplyToIndex(patch string) error {
+	files, err := hunk.Parse(patch)
+	if err != nil {
+		return fmt.Errorf("could not parse the patch: %w", err)
+	}
+
+	for _, f := range files {
+		if file := r.file(f.NewPath); file == nil || file.Worktree == ' ' || file.Worktree == '?' {
+			return fmt.Errorf("could not apply the patch to %s, it has no unstaged changes", f.NewPath)
+		}
+	}
+	for _, f := range files {
+		if file := r.file(f.NewPath); file.Index == ' ' {
+			file.Index = file.Worktree
+		}
+	}
+
+	r.Patches = append(r.Patches, patch)
+	return nil
+}
+
+// Commit runs the commit-msg hook and records a commit of the staged changes, or of only the changes of the
+// files at the paths, authored by user.name and user.email of the settings.
+func (r *MemoryRepository) Commit(message string, only []string) error {
+	staged, err := stagedFiles(r.Files)
+	if err != nil {
+		return err
+	}
+
+	committed := func(file FileStatus) bool { return file.IsStaged() }
+	if len(only) > 0 {
+		for _, path := range only {
+			if file := r.file(path); file == nil || file.Index == '?' {
+				return fmt.Errorf("pathspec %q did not match any file known to git", path)
+			}
+		}
+		committed = func(file FileStatus) bool { return contains(only, file.Path) }
+	} else if len(staged) == 0 {
+		return errors.New("nothing to commit")
+	}
+
+	if hook, ok := r.Hooks["commit-msg"]; ok {
+		if err := hook(message); err != nil {
+			return err
+		}
+	}
+
+	var files []FileStatus
+	for _, file := range r.Files {
+		if committed(file) {
+			file.Index = ' '
+			if len(only) > 0 {
+				file.Worktree = ' '
+			}
+		}
+		if file.Index != ' ' || file.Worktree != ' ' {
+			files = append(files, file)
+		}
+	}
+	r.Files = files
+
+	entry := LogEntry{
+		SHA:     fmt.Sprintf("%040x", len(r.History)+1),
+		Author:  r.Settings["user.name"] + " <" + r.Settings["user.email"] + ">",
+		Message: strings.TrimSpace(message),
+	}
+	r.History = append([]LogEntry{entry}, r.History...)
+
+	return nil
+}
+
+// Log returns the commits in the revision range, newest first. A range is either a single revision or two
+// revisions separated by "..", and a revision is HEAD, a tag or the beginning of the SHA of a commit.
+func (r *MemoryRepository) Log(revisionRange string) ([]LogEntry, error) {
+	from, to, isRange := strings.Cut(revisionRange, "..")
+	if !isRange {
+		from, to = "", revisionRange
+	}
+
+	start, err := r.resolve(to)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the git history for %q: %w", revisionRange, err)
+	}
+
+	end := len(r.History)
+	if from != "" {
+		if end, err = r.resolve(from); err != nil {
+			return nil, fmt.Errorf("could not read the git history for %q: %w", revisionRange, err)
+		}
+	}
+
+	if end < start {
+		return nil, nil
+	}
+	return append([]LogEntry(nil), r.History[start:end]...), nil
+}
+
+// Authors returns the distinct authors of the history as "Name <email>", sorted.
+func (r *MemoryRepository) Authors() ([]string, error) {
+	authors := make([]string, 0, len(r.History))
+	for _, entry := range r.History {
+		authors = append(authors, entry.Author)
+	}
+	return sortedUnique(authors), nil
+}
+
+// Tags returns the tags pointing to commits of the history, the most recently created first.
+func (r *MemoryRepository) Tags() ([]string, error) {
+	var tags []string
+	for _, tag := range r.TagRefs {
+		if _, err := r.resolve(tag.SHA); err == nil {
+			tags = append(tags, tag.Name)
+		}
+	}
+	return tags, nil
+}
+
+// CreateTag creates a tag pointing to the newest commit of the history.
+func (r *MemoryRepository) CreateTag(name string, message string) error {
+	if len(r.History) == 0 {
+		return errors.New("could not resolve HEAD, there are no commits")
+	}
+	for _, tag := range r.TagRefs {
+		if tag.Name == name {
+			return fmt.Errorf("tag %q already exists", name)
+		}
+	}
+
+	r.TagRefs = append([]MemoryTag{{Name: name, SHA: r.History[0].SHA, Message: message}}, r.TagRefs...)
+	return nil
+}
+
+// Config returns the setting of the key, or an empty string when it is not set.
+func (r *MemoryRepository) Config(key string) (string, error) {
+	return r.Settings[key], nil
+}
+
+// HooksDir returns the directory set with core.hooksPath, or the hooks directory of .git in the root.
+func (r *MemoryRepository) HooksDir() (string, error) {
+	if hooksPath := r.Settings["core.hooksPath"]; hooksPath != "" {
+		return resolveHooksPath(r, hooksPath)
+	}
+	return filepath.Join(r.Dir, ".git", "hooks"), nil
+}
+
+// file returns the file with changes at the path, or nil when the file has no changes.
+func (r *MemoryRepository) file(path string) *FileStatus {
+	for i := range r.Files {
+		if r.Files[i].Path == path {
+			return &r.Files[i]
+		}
+	}
+	return nil
+}
+
+// resolve returns the index of the commit the revision points to in the history.
+func (r *MemoryRepository) resolve(revision string) (int, error) {
+	if revision == "" || revision == "HEAD" {
+		if len(r.History) == 0 {
+			return 0, errors.New("unknown revision HEAD, there are no commits")
+		}
+		return 0, nil
+	}
+
+	for _, tag := range r.TagRefs {
+		if tag.Name == revision {
+			revision = tag.SHA
+			break
+		}
+	}
+
+	for i, entry := range r.History {
+		if strings.HasPrefix(entry.SHA, revision) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown revision %q", revision)
+}
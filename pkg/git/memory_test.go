@@ -0,0 +1,285 @@
+package git
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryStageAndUnstage(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     FileStatus
+		staged   FileStatus
+		unstaged FileStatus
+	}{
+		{
+			name:     "modified",
+			file:     FileStatus{Path: "a.go", Index: ' ', Worktree: 'M'},
+			staged:   FileStatus{Path: "a.go", Index: 'M', Worktree: ' '},
+			unstaged: FileStatus{Path: "a.go", Index: ' ', Worktree: 'M'},
+		},
+		{
+			name:     "untracked",
+			file:     FileStatus{Path: "a.go", Index: '?', Worktree: '?'},
+			staged:   FileStatus{Path: "a.go", Index: 'A', Worktree: ' '},
+			unstaged: FileStatus{Path: "a.go", Index: '?', Worktree: '?'},
+		},
+		{
+			name:     "deleted",
+			file:     FileStatus{Path: "a.go", Index: ' ', Worktree: 'D'},
+			staged:   FileStatus{Path: "a.go", Index: 'D', Worktree: ' '},
+			unstaged: FileStatus{Path: "a.go", Index: ' ', Worktree: 'D'},
+		},
+		{
+			name:     "partially staged",
+			file:     FileStatus{Path: "a.go", Index: 'M', Worktree: 'M'},
+			staged:   FileStatus{Path: "a.go", Index: 'M', Worktree: ' '},
+			unstaged: FileStatus{Path: "a.go", Index: ' ', Worktree: 'M'},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MemoryRepository{Files: []FileStatus{tt.file}}
+
+			if err := r.Stage([]string{"a.go"}); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if r.Files[0] != tt.staged {
+				t.Errorf("got %+v after staging, want %+v", r.Files[0], tt.staged)
+			}
+			if !r.Files[0].IsStaged() {
+				t.Errorf("got IsStaged false after staging %+v", r.Files[0])
+			}
+
+			if err := r.Unstage([]string{"a.go"}); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if r.Files[0] != tt.unstaged {
+				t.Errorf("got %+v after unstaging, want %+v", r.Files[0], tt.unstaged)
+			}
+		})
+	}
+
+	r := &MemoryRepository{}
+	if err := r.Stage([]string{"unknown.go"}); err == nil {
+		t.Error("staging an unknown file succeeded, want an error")
+	}
+}
+
+func TestMemoryCommit(t *testing.T) {
+	r := &MemoryRepository{
+		Files: []FileStatus{
+			{Path: "a.go", Index: 'M', Worktree: ' '},
+			{Path: "b.go", Index: 'M', Worktree: 'M'},
+			{Path: "c.go", Index: ' ', Worktree: 'M'},
+		},
+		Settings: map[string]string{"user.name": "Jane Doe", "user.email": "jane@example.com"},
+	}
+
+	if err := r.Commit("feat: Change a and b\n", nil); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := []FileStatus{{Path: "b.go", Index: ' ', Worktree: 'M'}, {Path: "c.go", Index: ' ', Worktree: 'M'}}
+	if !reflect.DeepEqual(r.Files, want) {
+		t.Errorf("got files %+v, want %+v", r.Files, want)
+	}
+
+	wantEntry := LogEntry{SHA: "0000000000000000000000000000000000000001", Author: "Jane Doe <jane@example.com>", Message: "feat: Change a and b"}
+	if len(r.History) != 1 || r.History[0] != wantEntry {
+		t.Errorf("got history %+v, want %+v", r.History, wantEntry)
+	}
+
+	if err := r.Commit("fix: Nothing", nil); err == nil {
+		t.Error("committing with nothing staged succeeded, want an error")
+	}
+}
+
+func TestMemoryCommitWithUnmergedFiles(t *testing.T) {
+	r := &MemoryRepository{Files: []FileStatus{
+		{Path: "a.go", Index: 'M', Worktree: ' '},
+		{Path: "b.go", Index: 'U', Worktree: 'U'},
+	}}
+
+	for _, only := range [][]string{nil, {"a.go"}} {
+		var unmerged *UnmergedError
+		if err := r.Commit("fix: Change a", only); !errors.As(err, &unmerged) {
+			t.Errorf("got error %v committing %q, want an *UnmergedError", err, only)
+		}
+	}
+	if len(r.History) != 0 {
+		t.Errorf("got commits %+v, want none", r.History)
+	}
+}
+
+func TestMemoryCommitOnly(t *testing.T) {
+	r := &MemoryRepository{Files: []FileStatus{
+		{Path: "a.go", Index: 'M', Worktree: ' '},
+		{Path: "b.go", Index: ' ', Worktree: 'M'},
+		{Path: "new.go", Index: '?', Worktree: '?'},
+	}}
+
+	if err := r.Commit("fix: Change b", []string{"new.go"}); err == nil {
+		t.Error("committing only an untracked file succeeded, want an error")
+	}
+
+	if err := r.Commit("fix: Change b", []string{"b.go"}); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := []FileStatus{{Path: "a.go", Index: 'M', Worktree: ' '}, {Path: "new.go", Index: '?', Worktree: '?'}}
+	if !reflect.DeepEqual(r.Files, want) {
+		t.Errorf("got files %+v, want %+v", r.Files, want)
+	}
+}
+
+func TestMemoryCommitMsgHook(t *testing.T) {
+	errRejected := errors.New("rejected")
+
+	var messages []string
+	r := &MemoryRepository{
+		Files: []FileStatus{{Path: "a.go", Index: 'M', Worktree: ' '}},
+		Hooks: map[string]func(string) error{
+			"commit-msg": func(message string) error {
+				messages = append(messages, message)
+				if message == "bad" {
+					return errRejected
+				}
+				return nil
+			},
+		},
+	}
+
+	if err := r.Commit("bad", nil); !errors.Is(err, errRejected) {
+		t.Errorf("got error %v, want the error of the hook", err)
+	}
+	if len(r.History) != 0 || !r.Files[0].IsStaged() {
+		t.Errorf("the rejected commit changed the repository: %+v, %+v", r.History, r.Files)
+	}
+
+	if err := r.Commit("feat: Good", nil); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if !reflect.DeepEqual(messages, []string{"bad", "feat: Good"}) {
+		t.Errorf("got hook messages %q, want both messages", messages)
+	}
+}
+
+func TestMemoryLogAndTags(t *testing.T) {
+	r := &MemoryRepository{
+		History: []LogEntry{
+			{SHA: "cccc", Message: "fix: Third"},
+			{SHA: "bbbb", Message: "feat: Second"},
+			{SHA: "aaaa", Message: "chore: First"},
+		},
+		TagRefs: []MemoryTag{{Name: "v1.1.0", SHA: "bbbb"}, {Name: "v1.0.0", SHA: "aaaa"}, {Name: "gone", SHA: "dddd"}},
+	}
+
+	tests := []struct {
+		revisionRange string
+		want          []string
+		wantErr       bool
+	}{
+		{revisionRange: "HEAD", want: []string{"cccc", "bbbb", "aaaa"}},
+		{revisionRange: "v1.0.0..HEAD", want: []string{"cccc", "bbbb"}},
+		{revisionRange: "v1.1.0..", want: []string{"cccc"}},
+		{revisionRange: "aaaa..bbbb", want: []string{"bbbb"}},
+		{revisionRange: "cccc..aaaa"},
+		{revisionRange: "v2.0.0..HEAD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.revisionRange, func(t *testing.T) {
+			log, err := r.Log(tt.revisionRange)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", log)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			var shas []string
+			for _, entry := range log {
+				shas = append(shas, entry.SHA)
+			}
+			if !reflect.DeepEqual(shas, tt.want) {
+				t.Errorf("got %v, want %v", shas, tt.want)
+			}
+		})
+	}
+
+	if tags, _ := r.Tags(); !reflect.DeepEqual(tags, []string{"v1.1.0", "v1.0.0"}) {
+		t.Errorf("got tags %v, want the tags of the history", tags)
+	}
+
+	if err := r.CreateTag("v1.1.1", "Release v1.1.1"); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if err := r.CreateTag("v1.1.1", "Release v1.1.1"); err == nil {
+		t.Error("creating an existing tag succeeded, want an error")
+	}
+	if tags, _ := r.Tags(); len(tags) != 3 || tags[0] != "v1.1.1" {
+		t.Errorf("got tags %v, want v1.1.1 first", tags)
+	}
+}
+
+func TestMemoryDiffAndApplyToIndex(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-package a\n+package b\n"
+	r := &MemoryRepository{
+		Files:         []FileStatus{{Path: "a.go", Index: ' ', Worktree: 'M'}, {Path: "b.go", Index: 'M', Worktree: ' '}},
+		UnstagedDiffs: map[string]string{"a.go": diff},
+		StagedDiffs:   map[string]string{"b.go": "staged"},
+	}
+
+	if got, _ := r.Diff(false, ""); got != diff {
+		t.Errorf("got unstaged diff %q, want %q", got, diff)
+	}
+	if got, _ := r.Diff(true, "b.go"); got != "staged" {
+		t.Errorf("got staged diff %q, want %q", got, "staged")
+	}
+
+	if err := r.ApplyToIndex(diff); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if want := (FileStatus{Path: "a.go", Index: 'M', Worktree: 'M'}); r.Files[0] != want {
+		t.Errorf("got %+v, want %+v", r.Files[0], want)
+	}
+	if !reflect.DeepEqual(r.Patches, []string{diff}) {
+		t.Errorf("got patches %q, want the applied patch", r.Patches)
+	}
+
+	if err := r.ApplyToIndex("diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -1 +1 @@\n-x\n+y\n"); err == nil {
+		t.Error("applying a patch to a file without unstaged changes succeeded, want an error")
+	}
+}
+
+func TestMemoryHooksDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		hooksPath string
+		want      string
+	}{
+		{name: "default", want: "/repo/.git/hooks"},
+		{name: "relative core.hooksPath", hooksPath: ".githooks", want: "/repo/.githooks"},
+		{name: "absolute core.hooksPath", hooksPath: "/etc/hooks", want: "/etc/hooks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MemoryRepository{Dir: "/repo", Settings: map[string]string{"core.hooksPath": tt.hooksPath}}
+
+			got, err := r.HooksDir()
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,437 @@
+/*
+Package git provides access to the Git repository CommitSense operates on.
+
+This file contains the diffs and the patches of the go-git implementation of the repository: the versions of the
+files in HEAD, in the index and in the working tree are diffed with the unified diff encoder of go-git, and
+patches are applied to the index by writing the patched contents as new blobs.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package git
+
+import (
+	"bytes"
+	"commitsense/pkg/hunk"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// binarySniffLen is the number of bytes searched for a NUL byte to tell binary files apart, as git does.
+const binarySniffLen = 8000
+
+// patch is a diff of several files, implementing the Patch interface of go-git.
+type patch []fdiff.FilePatch
+
+func (p patch) FilePatches() []fdiff.FilePatch {
+	return p
+}
+
+func (p patch) Message() string {
+	return ""
+}
+
+// patchFile is a version of a file in a diff, implementing the File interface of go-git.
+type patchFile struct {
+	path    string
+	mode    filemode.FileMode
+	hash    plumbing.Hash
+	content []byte
+}
+
+func (f *patchFile) Hash() plumbing.Hash {
+	return f.hash
+}
+
+func (f *patchFile) Mode() filemode.FileMode {
+	return f.mode
+}
+
+func (f *patchFile) Path() string {
+	return f.path
+}
+
+// filePatch is the diff of a single file, implementing the FilePatch interface of go-git. A nil from is a new
+// file and a nil to is a deleted file.
+type filePatch struct {
+	from     *patchFile
+	to       *patchFile
+	isBinary bool
+	chunks   []fdiff.Chunk
+}
+
+func (p *filePatch) IsBinary() bool {
+	return p.isBinary
+}
+
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	// A nil *patchFile must be returned as a nil interface for the encoder to tell new and deleted files apart.
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func (p *filePatch) Chunks() []fdiff.Chunk {
+	return p.chunks
+}
+
+// chunk is a part of a file added, deleted or left as it is, implementing the Chunk interface of go-git.
+type chunk struct {
+	content   string
+	operation fdiff.Operation
+}
+
+func (c chunk) Content() string {
+	return c.content
+}
+
+func (c chunk) Type() fdiff.Operation {
+	return c.operation
+}
+
+// newFilePatch diffs the versions of a file, returning nil when they are the same.
+func newFilePatch(from *patchFile, to *patchFile) *filePatch {
+	if from == nil && to == nil {
+		return nil
+	}
+	if from != nil && to != nil && from.hash == to.hash && from.mode == to.mode {
+		return nil
+	}
+
+	p := &filePatch{from: from, to: to}
+	if isBinary(from) || isBinary(to) {
+		p.isBinary = true
+		return p
+	}
+
+	var fromContent, toContent string
+	if from != nil {
+		fromContent = string(from.content)
+	}
+	if to != nil {
+		toContent = string(to.content)
+	}
+
+	for _, d := range diff.Do(fromContent, toContent) {
+		operation := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			operation = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			operation = fdiff.Delete
+		}
+		p.chunks = append(p.chunks, chunk{content: d.Text, operation: operation})
+	}
+
+	return p
+}
+
+// isBinary reports whether the file has a NUL byte in its beginning.
+func isBinary(f *patchFile) bool {
+	if f == nil {
+		return false
+	}
+	content := f.content
+	if len(content) > binarySniffLen {
+		content = content[:binarySniffLen]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// headTree returns the tree of the commit HEAD points to, or nil when there are no commits yet.
+func headTree(repo *gogit.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve HEAD: %w", err)
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// treeFile returns the version of the file at the path in the tree, or nil when the tree has no such file.
+func treeFile(tree *object.Tree, path string) (*patchFile, error) {
+	if tree == nil {
+		return nil, nil
+	}
+
+	file, err := tree.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return &patchFile{path: path, mode: file.Mode, hash: file.Hash, content: []byte(content)}, nil
+}
+
+// indexFile returns the version of the file at the path in the index, or nil when the index has no such file.
+func indexFile(repo *gogit.Repository, idx *index.Index, path string) (*patchFile, error) {
+	entry, err := idx.Entry(path)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := readBlob(repo, entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return &patchFile{path: path, mode: entry.Mode, hash: entry.Hash, content: content}, nil
+}
+
+// worktreeFile returns the version of the file at the path in the working tree, or nil when it was deleted.
+// The content of a symbolic link is the path it points to, as in Git.
+func worktreeFile(worktree *gogit.Worktree, path string) (*patchFile, error) {
+	info, err := worktree.Filesystem.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := filemode.NewFromOSFileMode(info.Mode())
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	if mode == filemode.Symlink {
+		target, err := worktree.Filesystem.Readlink(path)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(target)
+	} else {
+		file, err := worktree.Filesystem.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if content, err = io.ReadAll(file); err != nil {
+			return nil, err
+		}
+	}
+
+	return &patchFile{path: path, mode: mode, hash: plumbing.ComputeHash(plumbing.BlobObject, content), content: content}, nil
+}
+
+// diffFiles returns the two versions of the file to compare: the versions in the HEAD tree and in the index
+// when cached is set, and the versions in the index and in the working tree otherwise.
+func diffFiles(repo *gogit.Repository, idx *index.Index, head *object.Tree, worktree *gogit.Worktree, file FileStatus, cached bool) (*patchFile, *patchFile, error) {
+	if !cached {
+		from, err := indexFile(repo, idx, file.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		to, err := worktreeFile(worktree, file.Path)
+		return from, to, err
+	}
+
+	oldPath := file.Path
+	if file.OldPath != "" {
+		oldPath = file.OldPath
+	}
+	from, err := treeFile(head, oldPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := indexFile(repo, idx, file.Path)
+	return from, to, err
+}
+
+// applyToIndex applies the hunks of the file to its version in the index, adding new files to the index and
+// removing deleted ones. The mode of an existing file is kept.
+func applyToIndex(repo *gogit.Repository, idx *index.Index, file hunk.File) error {
+	if file.IsBinary {
+		return errors.New("binary patches need the git binary")
+	}
+
+	isNew, isDeleted, mode := parseFileHeader(file.Header)
+
+	var content []byte
+	entry, err := idx.Entry(file.OldPath)
+	switch {
+	case isNew && err == nil:
+		return errors.New("the file already exists in the index")
+	case !isNew && err != nil:
+		return fmt.Errorf("%s is not in the index: %w", file.OldPath, err)
+	case !isNew:
+		mode = entry.Mode
+		if content, err = readBlob(repo, entry.Hash); err != nil {
+			return err
+		}
+	}
+
+	patched, err := applyHunks(content, file.Hunks)
+	if err != nil {
+		return err
+	}
+
+	if isDeleted {
+		if len(patched) > 0 {
+			return errors.New("the file is deleted but the patch leaves content in it")
+		}
+		_, err := idx.Remove(file.OldPath)
+		return err
+	}
+
+	hash, err := writeBlob(repo, patched)
+	if err != nil {
+		return err
+	}
+
+	if !isNew && file.OldPath != file.NewPath {
+		if _, err := idx.Remove(file.OldPath); err != nil {
+			return err
+		}
+	}
+	if entry, err = idx.Entry(file.NewPath); err != nil {
+		entry = idx.Add(file.NewPath)
+	}
+
+	// The file information of the entry is cleared, as it no longer describes the file in the working tree
+	// and would make git take the working tree for unchanged.
+	*entry = index.Entry{Name: entry.Name, Hash: hash, Mode: mode, Size: uint32(len(patched)), ModifiedAt: time.Unix(0, 0)}
+
+	return nil
+}
+
+// parseFileHeader returns whether the header of a file patch adds or deletes the file, and the mode of a new file.
+func parseFileHeader(header []string) (isNew bool, isDeleted bool, mode filemode.FileMode) {
+	mode = filemode.Regular
+	for _, line := range header {
+		switch {
+		case line == "--- /dev/null":
+			isNew = true
+		case line == "+++ /dev/null":
+			isDeleted = true
+		case strings.HasPrefix(line, "new file mode "):
+			if m, err := filemode.New(strings.TrimPrefix(line, "new file mode ")); err == nil {
+				mode = m
+			}
+		}
+	}
+	return isNew, isDeleted, mode
+}
+
+// applyHunks applies the hunks, in the order they appear in the file, to the content. Each hunk must apply at
+// the line it starts from.
+func applyHunks(content []byte, hunks []hunk.Hunk) ([]byte, error) {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var patched []string
+	pos := 0
+	for _, h := range hunks {
+		// A hunk adding lines only starts from the line the lines are added after.
+		start := h.OldStart - 1
+		if h.OldLines == 0 {
+			start = h.OldStart
+		}
+		if start < pos || start > len(lines) {
+			return nil, fmt.Errorf("hunk %s does not apply", h.Header())
+		}
+
+		patched = append(patched, lines[pos:start]...)
+		pos = start
+
+		for i, line := range h.Lines {
+			if line[0] == '\\' {
+				continue
+			}
+
+			// The "\ No newline at end of file" marker applies to the line before it.
+			text := line[1:]
+			if i+1 == len(h.Lines) || h.Lines[i+1][0] != '\\' {
+				text += "\n"
+			}
+
+			switch line[0] {
+			case ' ', '-':
+				if pos == len(lines) || lines[pos] != text {
+					return nil, fmt.Errorf("hunk %s does not apply", h.Header())
+				}
+				pos++
+				if line[0] == ' ' {
+					patched = append(patched, text)
+				}
+			case '+':
+				patched = append(patched, text)
+			}
+		}
+	}
+	patched = append(patched, lines[pos:]...)
+
+	return []byte(strings.Join(patched, "")), nil
+}
+
+// readBlob returns the content of the blob.
+func readBlob(repo *gogit.Repository, hash plumbing.Hash) ([]byte, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// writeBlob stores the content as a blob and returns its hash.
+func writeBlob(repo *gogit.Repository, content []byte) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(content)))
+
+	writer, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := writer.Write(content); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := writer.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return repo.Storer.SetEncodedObject(obj)
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"commitsense/pkg/hunk"
+	"testing"
+)
+
+func TestApplyHunks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		hunks   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "change a line",
+			content: "one\ntwo\nthree\n",
+			hunks:   "@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n",
+			want:    "one\nTWO\nthree\n",
+		},
+		{
+			name:    "several hunks",
+			content: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			hunks:   "@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -8,2 +8,3 @@\n 8\n+8.5\n 9\n",
+			want:    "one\n2\n3\n4\n5\n6\n7\n8\n8.5\n9\n",
+		},
+		{
+			name:    "add to an empty file",
+			content: "",
+			hunks:   "@@ -0,0 +1,2 @@\n+one\n+two\n",
+			want:    "one\ntwo\n",
+		},
+		{
+			name:    "add after a line",
+			content: "one\nthree\n",
+			hunks:   "@@ -1,0 +2 @@\n+two\n",
+			want:    "one\ntwo\nthree\n",
+		},
+		{
+			name:    "delete every line",
+			content: "one\ntwo\n",
+			hunks:   "@@ -1,2 +0,0 @@\n-one\n-two\n",
+			want:    "",
+		},
+		{
+			name:    "add a newline at the end",
+			content: "one\ntwo",
+			hunks:   "@@ -1,2 +1,2 @@\n one\n-two\n\\ No newline at end of file\n+two\n",
+			want:    "one\ntwo\n",
+		},
+		{
+			name:    "remove the newline at the end",
+			content: "one\n",
+			hunks:   "@@ -1 +1 @@\n-one\n+one\n\\ No newline at end of file\n",
+			want:    "one",
+		},
+		{
+			name:    "context does not match",
+			content: "one\ntwo\nthree\n",
+			hunks:   "@@ -1,3 +1,3 @@\n one\n-zwei\n+TWO\n three\n",
+			wantErr: true,
+		},
+		{
+			name:    "hunk past the end",
+			content: "one\n",
+			hunks:   "@@ -5,1 +5,1 @@\n-five\n+FIVE\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := hunk.Parse("diff --git a/f b/f\n--- a/f\n+++ b/f\n" + tt.hunks)
+			if err != nil {
+				t.Fatalf("parsing the hunks: %v", err)
+			}
+
+			got, err := applyHunks([]byte(tt.content), files[0].Hunks)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+/*
+Package git provides access to the Git repository CommitSense operates on.
+
+This package defines the Repository interface covering the status, the staged files, the diffs, committing, the
+history, the tags, the configuration and the hooks of a repository, along with three implementations of it: one
+running the git binary, one using go-git for environments without git, and an in-memory fake for tests.
+
+Usage:
+  - Call the Current function to get the repository of the working directory.
+  - Call the Use function to replace it, e.g. with a MemoryRepository when testing a command.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Repository is a Git repository with a working tree.
+type Repository interface {
+	// Root returns the top-level directory of the working tree.
+	Root() (string, error)
+	// Status returns the files with changes in the working tree or in the index, untracked files included.
+	Status() ([]FileStatus, error)
+	// StagedFiles returns the files with changes staged for the next commit, or an *UnmergedError when
+	// files have unresolved conflicts.
+	StagedFiles() ([]StagedFile, error)
+	// Stage adds the current content of the files, including deletions, to the index.
+	Stage(paths []string) error
+	// Unstage removes the staged changes of the files from the index, leaving the working tree untouched.
+	Unstage(paths []string) error
+	// Diff returns the changes of the file at the path, or of all the files when the path is empty, as a unified
+	// diff with paths relative to the root: the changes staged in the index when cached is set, and the changes
+	// of the working tree not yet staged otherwise. Untracked files are left out.
+	Diff(cached bool, path string) (string, error)
+	// ApplyToIndex applies the patch, a unified diff with paths relative to the root, to the index without
+	// touching the working tree.
+	ApplyToIndex(patch string) error
+	// Commit creates a commit with the message from exactly the changes staged in the index. When paths are
+	// given, only the current content of the files at the paths is committed instead, as with
+	// `git commit --only`, and the other staged changes are left in the index.
+	Commit(message string, only []string) error
+	// Log returns the commits in the revision range, e.g. "origin/main..HEAD", newest first.
+	// Merge commits are left out as their messages are generated by Git.
+	Log(revisionRange string) ([]LogEntry, error)
+	// Authors returns the distinct authors of the history of HEAD as "Name <email>", sorted.
+	Authors() ([]string, error)
+	// Tags returns the tags reachable from HEAD, the most recently created first.
+	Tags() ([]string, error)
+	// CreateTag creates an annotated tag pointing to HEAD.
+	CreateTag(name string, message string) error
+	// Config returns the value of the configuration key, e.g. "core.hooksPath", or an empty string when it
+	// is not set.
+	Config(key string) (string, error)
+	// HooksDir returns the directory Git runs the hooks of the repository from.
+	HooksDir() (string, error)
+}
+
+// StatusCode is the status of a file in the index or in the working tree, as in `git status --porcelain`:
+// ' ' for unmodified, 'M' for modified, 'T' for a changed file type, 'A' for added, 'D' for deleted,
+// 'R' for renamed, 'C' for copied, 'U' for unmerged and '?' for untracked.
+type StatusCode byte
+
+// String returns the status code as a single character.
+func (c StatusCode) String() string {
+	return string(rune(c))
+}
+
+// MarshalText encodes the status code as a single character.
+func (c StatusCode) MarshalText() ([]byte, error) {
+	return []byte{byte(c)}, nil
+}
+
+// UnmarshalText decodes a status code from a single character.
+func (c *StatusCode) UnmarshalText(text []byte) error {
+	if len(text) != 1 {
+		return fmt.Errorf("invalid status code %q", text)
+	}
+	*c = StatusCode(text[0])
+	return nil
+}
+
+// FileStatus represents a file with changes in the working tree or in the index.
+type FileStatus struct {
+	Path string
+	// OldPath is the path the file was renamed or copied from.
+	OldPath  string
+	Index    StatusCode
+	Worktree StatusCode
+	// IsSubmodule reports whether the path is a submodule.
+	IsSubmodule bool
+}
+
+// IsStaged reports whether the file has changes staged for the next commit. Unmerged files are not staged
+// until their conflicts are resolved.
+func (f FileStatus) IsStaged() bool {
+	return !f.IsUnmerged() && strings.IndexByte("MTADRC", byte(f.Index)) >= 0
+}
+
+// IsUnmerged reports whether the file has conflicts left by a merge: both deleted (DD), added by us (AU),
+// deleted by them (UD), added by them (UA), deleted by us (DU), both added (AA) or both modified (UU).
+func (f FileStatus) IsUnmerged() bool {
+	if f.Index == 'U' || f.Worktree == 'U' {
+		return true
+	}
+	return (f.Index == 'D' && f.Worktree == 'D') || (f.Index == 'A' && f.Worktree == 'A')
+}
+
+// UnmergedError is returned for the staged files while the index has files with unresolved conflicts,
+// which must not be committed.
+type UnmergedError struct {
+	Paths []string
+}
+
+func (e *UnmergedError) Error() string {
+	return fmt.Sprintf("unmerged files, resolve the conflicts and stage the files first: %s", strings.Join(e.Paths, ", "))
+}
+
+// StagedFile represents a file with changes staged for the next commit.
+type StagedFile struct {
+	// Status is the status code of the file in the index: 'M', 'T', 'A', 'D', 'R' or 'C'.
+	Status StatusCode `json:"status"`
+	// OldPath is the path a renamed or copied file was renamed or copied from.
+	OldPath string `json:"old_path,omitempty"`
+	// Path is the path of the file, the new path of a renamed or copied file.
+	Path        string `json:"path"`
+	IsSubmodule bool   `json:"submodule,omitempty"`
+}
+
+// Paths returns the paths of the files, along with the old paths of the renamed files.
+func Paths(files []StagedFile) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.Status == 'R' {
+			paths = append(paths, file.OldPath)
+		}
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+// LogEntry represents a single commit read from the Git history.
+type LogEntry struct {
+	SHA string
+	// Author is the author of the commit as "Name <email>".
+	Author  string
+	Message string
+}
+
+var (
+	current   Repository
+	currentMu sync.Mutex
+)
+
+// Current returns the repository the commands operate on: the repository set with Use, or the repository of
+// the working directory, accessed through the git binary when it is installed and through go-git otherwise.
+func Current() Repository {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+
+	if current == nil {
+		if _, err := exec.LookPath("git"); err == nil {
+			current = &CommandRepository{}
+		} else {
+			current = &GoGitRepository{}
+		}
+	}
+
+	return current
+}
+
+// Use sets the repository the commands operate on.
+func Use(repo Repository) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+
+	current = repo
+}
+
+// CommitCommand returns the git command line creating a commit with the message from the index, or from
+// only the files at the paths when any are given.
+func CommitCommand(message string, only []string) []string {
+	command := []string{"git", "commit", "-m", message}
+	if len(only) > 0 {
+		command = append(append(command, "--only", "--"), only...)
+	}
+	return command
+}
+
+// stagedFiles returns the staged files in the status, or an *UnmergedError when files are unmerged.
+func stagedFiles(status []FileStatus) ([]StagedFile, error) {
+	var files []StagedFile
+	var unmerged []string
+	for _, file := range status {
+		if file.IsUnmerged() {
+			unmerged = append(unmerged, file.Path)
+		}
+		if file.IsStaged() {
+			files = append(files, StagedFile{
+				Status:      file.Index,
+				OldPath:     file.OldPath,
+				Path:        file.Path,
+				IsSubmodule: file.IsSubmodule,
+			})
+		}
+	}
+	if len(unmerged) > 0 {
+		return nil, &UnmergedError{Paths: unmerged}
+	}
+	return files, nil
+}
+
+// sortedUnique sorts the strings and removes the duplicates.
+func sortedUnique(values []string) []string {
+	sort.Strings(values)
+
+	var unique []string
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// contains reports whether the value is one of the values.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveHooksPath resolves the value of core.hooksPath: a leading "~/" is expanded to the home directory
+// and relative paths are resolved from the root of the repository.
+func resolveHooksPath(repo Repository, hooksPath string) (string, error) {
+	if strings.HasPrefix(hooksPath, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		hooksPath = filepath.Join(home, hooksPath[2:])
+	}
+	if filepath.IsAbs(hooksPath) {
+		return hooksPath, nil
+	}
+
+	root, err := repo.Root()
+	if err != nil {
+		return "", errors.New("could not find the repository root, is this a git repository?")
+	}
+	return filepath.Join(root, hooksPath), nil
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFileStatusIsStaged(t *testing.T) {
+	tests := []struct {
+		index        StatusCode
+		worktree     StatusCode
+		wantStaged   bool
+		wantUnmerged bool
+	}{
+		{index: 'M', worktree: ' ', wantStaged: true},
+		{index: 'A', worktree: 'M', wantStaged: true},
+		{index: 'D', worktree: ' ', wantStaged: true},
+		{index: 'R', worktree: ' ', wantStaged: true},
+		{index: ' ', worktree: 'M'},
+		{index: '?', worktree: '?'},
+		{index: 'D', worktree: 'D', wantUnmerged: true},
+		{index: 'A', worktree: 'U', wantUnmerged: true},
+		{index: 'U', worktree: 'D', wantUnmerged: true},
+		{index: 'U', worktree: 'A', wantUnmerged: true},
+		{index: 'D', worktree: 'U', wantUnmerged: true},
+		{index: 'A', worktree: 'A', wantUnmerged: true},
+		{index: 'U', worktree: 'U', wantUnmerged: true},
+	}
+
+	for _, tt := range tests {
+		file := FileStatus{Path: "a.go", Index: tt.index, Worktree: tt.worktree}
+		t.Run(tt.index.String()+tt.worktree.String(), func(t *testing.T) {
+			if got := file.IsStaged(); got != tt.wantStaged {
+				t.Errorf("got staged %v, want %v", got, tt.wantStaged)
+			}
+			if got := file.IsUnmerged(); got != tt.wantUnmerged {
+				t.Errorf("got unmerged %v, want %v", got, tt.wantUnmerged)
+			}
+		})
+	}
+}
+
+func TestStagedFilesWithUnmergedFiles(t *testing.T) {
+	status := []FileStatus{
+		{Path: "a.go", Index: 'M', Worktree: ' '},
+		{Path: "b.go", Index: 'U', Worktree: 'U'},
+		{Path: "c.go", Index: 'A', Worktree: 'A'},
+	}
+
+	files, err := stagedFiles(status)
+
+	var unmerged *UnmergedError
+	if !errors.As(err, &unmerged) {
+		t.Fatalf("got files %+v and error %v, want an *UnmergedError", files, err)
+	}
+	if want := []string{"b.go", "c.go"}; !reflect.DeepEqual(unmerged.Paths, want) {
+		t.Errorf("got unmerged paths %q, want %q", unmerged.Paths, want)
+	}
+}
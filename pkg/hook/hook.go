@@ -0,0 +1,143 @@
+/*
+Package hook provides functionality for installing CommitSense as Git hooks.
+
+This package writes the commit-msg and prepare-commit-msg hook scripts into the hooks directory of the
+current Git repository, or into the directory configured with core.hooksPath. The scripts delegate to the
+`commitsense hook run` command, so plain `git commit` gets the same validation as `commitsense commit`.
+
+Usage:
+  - Call the Install function to write a hook script, and the Uninstall function to remove it.
+  - Hooks that were not written by CommitSense are never overwritten or removed.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package hook
+
+import (
+	"commitsense/pkg/git"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// CommitMsg is the name of the hook validating the commit message.
+	CommitMsg = "commit-msg"
+	// PrepareCommitMsg is the name of the hook prompting for the commit message.
+	PrepareCommitMsg = "prepare-commit-msg"
+
+	marker = "# Installed by CommitSense"
+)
+
+// Names lists the hooks managed by CommitSense.
+var Names = []string{CommitMsg, PrepareCommitMsg}
+
+// ErrForeignHook is returned when a hook exists that was not written by CommitSense.
+var ErrForeignHook = errors.New("hook exists and was not installed by CommitSense")
+
+// ErrNotInstalled is returned when uninstalling a hook that does not exist.
+var ErrNotInstalled = errors.New("no hook installed")
+
+var scripts = map[string]string{
+	CommitMsg: `#!/bin/sh
+` + marker + `, remove with "commitsense hook uninstall".
+if ! command -v commitsense >/dev/null 2>&1; then
+	echo "commitsense not found in PATH, skipping the commit message validation" >&2
+	exit 0
+fi
+
+exec commitsense hook run commit-msg "$@"
+`,
+	PrepareCommitMsg: `#!/bin/sh
+` + marker + `, remove with "commitsense hook uninstall".
+# The interactive prompts are only started when git did not get a message from -m, -F, a template,
+# a merge or an amended commit, and when a terminal is available.
+if [ -n "$2" ] || ! command -v commitsense >/dev/null 2>&1; then
+	exit 0
+fi
+
+if (exec </dev/tty) 2>/dev/null; then
+	exec commitsense hook run prepare-commit-msg "$@" </dev/tty
+fi
+`,
+}
+
+// Dir returns the directory Git runs the hooks of the current repository from.
+// The core.hooksPath setting is respected, relative paths being resolved from the repository root.
+func Dir() (string, error) {
+	return git.Current().HooksDir()
+}
+
+// Install writes the named hook script into the hooks directory and returns its path.
+// An existing hook is only replaced if it was installed by CommitSense.
+func Install(name string) (string, error) {
+	script, ok := scripts[name]
+	if !ok {
+		return "", fmt.Errorf("unknown hook %q", name)
+	}
+
+	path, err := hookPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	if installed, err := isInstalledByCommitSense(path); err != nil {
+		return "", err
+	} else if !installed {
+		return path, ErrForeignHook
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	return path, os.WriteFile(path, []byte(script), 0o755) //nolint:gosec // hooks must be executable
+}
+
+// Uninstall removes the named hook script from the hooks directory and returns its path.
+// Hooks that were not installed by CommitSense are left untouched, and ErrNotInstalled is returned
+// when there is no hook to remove.
+func Uninstall(name string) (string, error) {
+	path, err := hookPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return path, ErrNotInstalled
+	} else if err != nil {
+		return "", err
+	}
+
+	if installed, err := isInstalledByCommitSense(path); err != nil {
+		return "", err
+	} else if !installed {
+		return path, ErrForeignHook
+	}
+
+	return path, os.Remove(path)
+}
+
+func hookPath(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// isInstalledByCommitSense reports whether the hook at path can be written by CommitSense,
+// that is it does not exist or it contains the CommitSense marker.
+func isInstalledByCommitSense(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(content), marker), nil
+}
@@ -0,0 +1,328 @@
+/*
+Package hunk provides functionality for parsing unified diffs into hunks and building patches from them.
+
+This package parses the output of `git diff` into files and hunks, splits hunks into smaller hunks and
+builds patches from a selection of hunks, which can be applied to the index with `git apply --cached`.
+
+Usage:
+  - Call the Parse function to parse the output of `git diff`.
+  - Call the Split method of a hunk to split it into hunks with a single block of changes each.
+  - Call the Patch method of a file to build a patch from the selected hunks of the file.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package hunk
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// File represents the changes made to a single file in a diff.
+type File struct {
+	// Header contains the lines before the first hunk, from "diff --git" to "+++".
+	Header   []string
+	OldPath  string
+	NewPath  string
+	IsBinary bool
+	Hunks    []Hunk
+}
+
+// Hunk represents a single hunk of changes, starting with a "@@ -a,b +c,d @@" header.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Section is the text following the hunk header, usually the enclosing function.
+	Section string
+	// Lines contains the lines of the hunk, each starting with ' ', '+', '-' or '\'.
+	Lines []string
+}
+
+// ParseError describes a diff that could not be parsed. Line is the 1-based line of the diff.
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Parse parses a unified diff in the format produced by `git diff` into files and their hunks.
+func Parse(diff string) ([]File, error) {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+
+	var files []File
+	var current *File
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, File{Header: []string{line}})
+			current = &files[len(files)-1]
+			current.OldPath, current.NewPath = parseDiffGitPaths(line)
+		case current == nil:
+			if line == "" {
+				continue
+			}
+			return nil, &ParseError{Line: i + 1, Message: "expected a \"diff --git\" line"}
+		case strings.HasPrefix(line, "@@"):
+			h, consumed, err := parseHunk(lines[i:], i+1)
+			if err != nil {
+				return nil, err
+			}
+			current.Hunks = append(current.Hunks, h)
+			i += consumed - 1
+		case len(current.Hunks) > 0:
+			if line == "" {
+				continue
+			}
+			return nil, &ParseError{Line: i + 1, Message: fmt.Sprintf("unexpected line %q after a hunk", line)}
+		default:
+			current.Header = append(current.Header, line)
+			parseHeaderLine(current, line)
+		}
+	}
+
+	return files, nil
+}
+
+// parseHunk parses the hunk starting at the first line and returns it with the number of lines it spans.
+func parseHunk(lines []string, lineNumber int) (Hunk, int, error) {
+	match := hunkHeaderPattern.FindStringSubmatch(lines[0])
+	if match == nil {
+		return Hunk{}, 0, &ParseError{Line: lineNumber, Message: fmt.Sprintf("malformed hunk header %q", lines[0])}
+	}
+
+	h := Hunk{
+		OldStart: atoi(match[1], 0),
+		OldLines: atoi(match[2], 1),
+		NewStart: atoi(match[3], 0),
+		NewLines: atoi(match[4], 1),
+		Section:  match[5],
+	}
+
+	oldRemaining, newRemaining := h.OldLines, h.NewLines
+	consumed := 1
+
+	for ; consumed < len(lines) && (oldRemaining > 0 || newRemaining > 0 || isNoNewlineMarker(lines[consumed])); consumed++ {
+		line := lines[consumed]
+		if line == "" {
+			// Some tools strip the trailing space of empty context lines.
+			line = " "
+		}
+
+		switch line[0] {
+		case ' ':
+			oldRemaining--
+			newRemaining--
+		case '-':
+			oldRemaining--
+		case '+':
+			newRemaining--
+		case '\\':
+		default:
+			return Hunk{}, 0, &ParseError{Line: lineNumber + consumed, Message: fmt.Sprintf("unexpected line %q in a hunk", line)}
+		}
+
+		if oldRemaining < 0 || newRemaining < 0 {
+			return Hunk{}, 0, &ParseError{Line: lineNumber + consumed, Message: "hunk is longer than its header states"}
+		}
+
+		h.Lines = append(h.Lines, line)
+	}
+
+	if oldRemaining > 0 || newRemaining > 0 {
+		return Hunk{}, 0, &ParseError{Line: lineNumber + consumed, Message: "hunk is shorter than its header states"}
+	}
+
+	return h, consumed, nil
+}
+
+func parseDiffGitPaths(line string) (string, string) {
+	paths := strings.TrimPrefix(line, "diff --git ")
+	if oldPath, newPath, found := strings.Cut(paths, " b/"); found {
+		return strings.TrimPrefix(oldPath, "a/"), newPath
+	}
+	return paths, paths
+}
+
+func parseHeaderLine(f *File, line string) {
+	switch {
+	case strings.HasPrefix(line, "--- "):
+		if path := strings.TrimPrefix(line, "--- "); path != "/dev/null" {
+			f.OldPath = strings.TrimPrefix(path, "a/")
+		}
+	case strings.HasPrefix(line, "+++ "):
+		if path := strings.TrimPrefix(line, "+++ "); path != "/dev/null" {
+			f.NewPath = strings.TrimPrefix(path, "b/")
+		}
+	case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+		f.IsBinary = true
+	}
+}
+
+func isNoNewlineMarker(line string) bool {
+	return strings.HasPrefix(line, "\\")
+}
+
+func atoi(s string, fallback int) int {
+	if s == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// Header returns the "@@ -a,b +c,d @@" header line of the hunk.
+func (h Hunk) Header() string {
+	header := fmt.Sprintf("@@ -%s +%s @@", formatRange(h.OldStart, h.OldLines), formatRange(h.NewStart, h.NewLines))
+	if h.Section != "" {
+		header += " " + h.Section
+	}
+	return header
+}
+
+// String returns the hunk in the unified diff format.
+func (h Hunk) String() string {
+	return h.Header() + "\n" + strings.Join(h.Lines, "\n") + "\n"
+}
+
+func formatRange(start int, lines int) string {
+	if lines == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// Split splits the hunk into smaller hunks that each contain a single block of consecutive changes,
+// in the same way as `git add --patch` does. The context lines between two blocks are shared by both
+// hunks. A hunk with a single block of changes is returned as is.
+func (h Hunk) Split() []Hunk {
+	var hunks []Hunk
+
+	oldLine, newLine := h.OldStart, h.NewStart
+	start := 0       // index of the first line of the current sub-hunk
+	lastChange := -1 // index of the last change line seen in the current sub-hunk
+	contextRun := -1 // index of the first context line after the last change, -1 if none
+	startOld, startNew := oldLine, newLine
+
+	flush := func(end int) {
+		hunks = append(hunks, newHunk(h.Lines[start:end], startOld, startNew, h.Section))
+	}
+
+	for i, line := range h.Lines {
+		switch line[0] {
+		case ' ':
+			if lastChange >= 0 && contextRun < 0 {
+				contextRun = i
+			}
+			oldLine++
+			newLine++
+			continue
+		case '\\':
+			continue
+		}
+
+		if contextRun >= 0 {
+			// A new block of changes starts after context lines: close the previous sub-hunk with all
+			// of the context lines and start the next one from the same context lines.
+			flush(i)
+			start = contextRun
+			startOld = oldLine - (i - contextRun)
+			startNew = newLine - (i - contextRun)
+			contextRun = -1
+		}
+
+		lastChange = i
+		if line[0] == '-' {
+			oldLine++
+		} else {
+			newLine++
+		}
+	}
+
+	if len(hunks) == 0 {
+		return []Hunk{h}
+	}
+
+	flush(len(h.Lines))
+
+	return hunks
+}
+
+// newHunk creates a hunk from the lines, counting the old and new line numbers they span.
+func newHunk(lines []string, oldStart int, newStart int, section string) Hunk {
+	h := Hunk{OldStart: oldStart, NewStart: newStart, Section: section, Lines: lines}
+	for _, line := range lines {
+		switch line[0] {
+		case ' ':
+			h.OldLines++
+			h.NewLines++
+		case '-':
+			h.OldLines++
+		case '+':
+			h.NewLines++
+		}
+	}
+	return h
+}
+
+// Patch builds a patch of the file containing only the given hunks, which must be in the order they
+// appear in the file. The new line numbers of the hunks are recalculated to account for the hunks
+// left out, and overlapping hunks, such as adjacent hunks created by Split, are merged.
+func (f File) Patch(hunks []Hunk) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, line := range f.Header {
+		sb.WriteString(line + "\n")
+	}
+
+	offset := 0
+	for _, h := range mergeOverlapping(hunks) {
+		h.NewStart = h.OldStart + offset
+		switch {
+		case h.OldLines == 0:
+			h.NewStart++
+		case h.NewLines == 0:
+			h.NewStart--
+		}
+		offset += h.NewLines - h.OldLines
+
+		sb.WriteString(h.String())
+	}
+
+	return sb.String()
+}
+
+func mergeOverlapping(hunks []Hunk) []Hunk {
+	merged := []Hunk{hunks[0]}
+
+	for _, next := range hunks[1:] {
+		last := &merged[len(merged)-1]
+		overlap := last.OldStart + last.OldLines - next.OldStart
+		if overlap <= 0 {
+			merged = append(merged, next)
+			continue
+		}
+
+		lines := append(append([]string{}, last.Lines...), next.Lines[overlap:]...)
+		*last = newHunk(lines, last.OldStart, last.NewStart, last.Section)
+	}
+
+	return merged
+}
@@ -0,0 +1,208 @@
+package hunk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []File {
+	t.Helper()
+
+	diff, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+
+	files, err := Parse(string(diff))
+	if err != nil {
+		t.Fatalf("parsing fixture %s: %v", name, err)
+	}
+
+	return files
+}
+
+func TestParseMultipleFiles(t *testing.T) {
+	files := readFixture(t, "multiple_files.diff")
+
+	tests := []struct {
+		oldPath  string
+		newPath  string
+		isBinary bool
+		hunks    int
+	}{
+		{oldPath: "added.txt", newPath: "added.txt", hunks: 1},
+		{oldPath: "image.bin", newPath: "image.bin", isBinary: true},
+		{oldPath: "nonl.txt", newPath: "nonl.txt", hunks: 1},
+		{oldPath: "numbers.txt", newPath: "numbers.txt", hunks: 3},
+		{oldPath: "removed.txt", newPath: "removed.txt", hunks: 1},
+	}
+
+	if len(files) != len(tests) {
+		t.Fatalf("got %d files, want %d", len(files), len(tests))
+	}
+
+	for i, tt := range tests {
+		f := files[i]
+		if f.OldPath != tt.oldPath || f.NewPath != tt.newPath {
+			t.Errorf("file %d: got paths %q -> %q, want %q -> %q", i, f.OldPath, f.NewPath, tt.oldPath, tt.newPath)
+		}
+		if f.IsBinary != tt.isBinary {
+			t.Errorf("file %d: got IsBinary %v, want %v", i, f.IsBinary, tt.isBinary)
+		}
+		if len(f.Hunks) != tt.hunks {
+			t.Errorf("file %d: got %d hunks, want %d", i, len(f.Hunks), tt.hunks)
+		}
+	}
+}
+
+func TestParseHunkHeaders(t *testing.T) {
+	files := readFixture(t, "multiple_files.diff")
+
+	tests := []struct {
+		file   int
+		hunk   int
+		header string
+		lines  int
+	}{
+		{file: 0, hunk: 0, header: "@@ -0,0 +1 @@", lines: 1},
+		{file: 2, hunk: 0, header: "@@ -1,3 +1,4 @@", lines: 8},
+		{file: 3, hunk: 1, header: "@@ -8,6 +8,7 @@", lines: 7},
+		{file: 4, hunk: 0, header: "@@ -1 +0,0 @@", lines: 1},
+	}
+
+	for _, tt := range tests {
+		h := files[tt.file].Hunks[tt.hunk]
+		if got := h.Header(); got != tt.header {
+			t.Errorf("file %d hunk %d: got header %q, want %q", tt.file, tt.hunk, got, tt.header)
+		}
+		if len(h.Lines) != tt.lines {
+			t.Errorf("file %d hunk %d: got %d lines, want %d", tt.file, tt.hunk, len(h.Lines), tt.lines)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		line int
+	}{
+		{
+			name: "text before the first file",
+			diff: "hello\ndiff --git a/x b/x\n",
+			line: 1,
+		},
+		{
+			name: "malformed hunk header",
+			diff: "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1,2 +1,2\n a\n",
+			line: 4,
+		},
+		{
+			name: "hunk shorter than its header",
+			diff: "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n-b\n+c\n",
+			line: 8,
+		},
+		{
+			name: "unexpected line in a hunk",
+			diff: "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n*b\n",
+			line: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.diff)
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("got error %v, want a *ParseError", err)
+			}
+			if parseErr.Line != tt.line {
+				t.Errorf("got error on line %d, want line %d: %v", parseErr.Line, tt.line, err)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	files := readFixture(t, "split.diff")
+
+	hunks := files[0].Hunks[0].Split()
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(hunks))
+	}
+
+	wantHeaders := []string{"@@ -1,7 +1,7 @@", "@@ -5,7 +5,7 @@"}
+	for i, h := range hunks {
+		if got := h.Header(); got != wantHeaders[i] {
+			t.Errorf("hunk %d: got header %q, want %q", i, got, wantHeaders[i])
+		}
+	}
+
+	if got := hunks[1].Lines[0]; got != " 5" {
+		t.Errorf("second hunk starts with %q, want the shared context line \" 5\"", got)
+	}
+}
+
+func TestSplitSingleBlock(t *testing.T) {
+	files := readFixture(t, "multiple_files.diff")
+
+	h := files[3].Hunks[0]
+	hunks := h.Split()
+	if len(hunks) != 1 || hunks[0].String() != h.String() {
+		t.Errorf("got %v, want the hunk itself", hunks)
+	}
+}
+
+func TestPatchRecalculatesNewStart(t *testing.T) {
+	files := readFixture(t, "multiple_files.diff")
+	numbers := files[3]
+
+	patch := numbers.Patch([]Hunk{numbers.Hunks[0], numbers.Hunks[2]})
+
+	if !strings.HasPrefix(patch, "diff --git a/numbers.txt b/numbers.txt\n") {
+		t.Errorf("patch does not start with the file header:\n%s", patch)
+	}
+	if !strings.Contains(patch, "@@ -1,5 +1,5 @@\n") {
+		t.Errorf("patch is missing the first hunk:\n%s", patch)
+	}
+	if !strings.Contains(patch, "@@ -22,7 +22,7 @@\n") {
+		t.Errorf("patch does not move the last hunk up after leaving out the insertion:\n%s", patch)
+	}
+	if strings.Contains(patch, "ten and a half") {
+		t.Errorf("patch contains a hunk that was not selected:\n%s", patch)
+	}
+}
+
+func TestPatchMergesSplitHunks(t *testing.T) {
+	files := readFixture(t, "split.diff")
+	split := files[0]
+
+	got := split.Patch(split.Hunks[0].Split())
+	want := split.Patch(split.Hunks)
+
+	if got != want {
+		t.Errorf("patch of all split hunks differs from the original patch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPatchNoNewline(t *testing.T) {
+	files := readFixture(t, "multiple_files.diff")
+	nonl := files[2]
+
+	patch := nonl.Patch(nonl.Hunks)
+	if strings.Count(patch, "\\ No newline at end of file") != 2 {
+		t.Errorf("patch lost the no newline markers:\n%s", patch)
+	}
+}
+
+func TestPatchWithoutHunks(t *testing.T) {
+	files := readFixture(t, "multiple_files.diff")
+
+	if patch := files[3].Patch(nil); patch != "" {
+		t.Errorf("got patch %q, want an empty patch", patch)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Package lint provides functionality for validating commit messages against the CommitSense configuration.
+
+This package parses commit messages in the Conventional Commits format and checks them against the lint rules,
+such as the allowed commit types and the maximum length of the header. The level and the options of each rule
+are set in the rules of the CommitSense configuration file.
+
+Usage:
+  - Call the Lint function with a commit message and a configuration to get the list of rule violations.
+  - Call the HasErrors function to check whether any of the violations is at the error level.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package lint
+
+import (
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ignoredPrefixes lists the prefixes of commit messages generated by Git itself, which are not linted.
+var ignoredPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// Violation represents a single rule a commit message does not follow.
+type Violation struct {
+	Rule string `json:"rule"`
+	// Level is the configured level of the rule, config.LevelWarn or config.LevelError.
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("[%s] %s", v.Rule, v.Message)
+}
+
+// message is a commit message being linted.
+type message struct {
+	// lines are the lines of the message without trailing whitespace and the skip ci marker.
+	lines  []string
+	commit *commit.Commit
+	cfg    *config.Config
+}
+
+// Lint checks the commit message against the rules of the configuration and returns the rule violations
+// found. Messages generated by Git, such as merge commits and fixups, are skipped.
+func Lint(msg string, cfg *config.Config) []Violation {
+	if IsIgnored(msg) {
+		return nil
+	}
+
+	c, err := commit.ParseCommitMessage(msg)
+	if err != nil {
+		return []Violation{{Rule: RuleHeaderFormat, Level: config.LevelError, Message: err.Error()}}
+	}
+
+	m := &message{commit: c, cfg: cfg}
+	for _, line := range strings.Split(strings.ReplaceAll(strings.TrimSpace(msg), "\r\n", "\n"), "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if strings.TrimSpace(line) != commit.SkipCIMarker {
+			m.lines = append(m.lines, line)
+		}
+	}
+
+	var violations []Violation
+	for _, rule := range Rules {
+		level, options := rule.Configure(cfg)
+		if level == config.LevelOff {
+			continue
+		}
+
+		for _, text := range rule.check(m, options) {
+			violations = append(violations, Violation{Rule: rule.Name, Level: level, Message: text})
+		}
+	}
+
+	return violations
+}
+
+// HasErrors reports whether any of the violations is at the error level.
+func HasErrors(violations []Violation) bool {
+	for _, violation := range violations {
+		if violation.Level == config.LevelError {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIgnored reports whether the commit message was generated by Git and should not be linted.
+func IsIgnored(msg string) bool {
+	for _, prefix := range ignoredPrefixes {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package lint
+
+import (
+	"commitsense/pkg/config"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   map[string]config.RuleConfig
+		message string
+		want    []Violation
+	}{
+		{
+			name:    "valid message",
+			message: "feat(api): Add pages\n\nThe body.\n\nRefs: #1",
+		},
+		{
+			name:    "unparsable message",
+			message: "Add pages",
+			want:    []Violation{{Rule: RuleHeaderFormat, Level: config.LevelError, Message: "line 1, column 4: expected ':' after the commit type"}},
+		},
+		{
+			name:    "header-format cannot be turned off",
+			rules:   map[string]config.RuleConfig{RuleHeaderFormat: {Level: config.LevelOff}, RuleTypeEnum: {Level: config.LevelOff}},
+			message: "Add pages",
+			want:    []Violation{{Rule: RuleHeaderFormat, Level: config.LevelError, Message: "line 1, column 4: expected ':' after the commit type"}},
+		},
+		{
+			name:    "violations at their default levels in the order of the rules",
+			message: "feat: add pages.\nThe body.",
+			want: []Violation{
+				{Rule: RuleSubjectCase, Level: config.LevelError, Message: "subject must be in sentence-case"},
+				{Rule: RuleSubjectFullStop, Level: config.LevelError, Message: `subject must not end with "."`},
+				{Rule: RuleBodyLeadingBlank, Level: config.LevelWarn, Message: "body must be separated from the header by a blank line"},
+			},
+		},
+		{
+			name: "levels overridden",
+			rules: map[string]config.RuleConfig{
+				RuleSubjectCase:      {Level: config.LevelOff},
+				RuleSubjectFullStop:  {Level: config.LevelWarn},
+				RuleBodyLeadingBlank: {Level: config.LevelError},
+			},
+			message: "feat: add pages.\nThe body.",
+			want: []Violation{
+				{Rule: RuleSubjectFullStop, Level: config.LevelWarn, Message: `subject must not end with "."`},
+				{Rule: RuleBodyLeadingBlank, Level: config.LevelError, Message: "body must be separated from the header by a blank line"},
+			},
+		},
+		{
+			name:    "rule off by default turned on",
+			rules:   map[string]config.RuleConfig{RuleTrailerExists: {Level: config.LevelWarn}},
+			message: "feat: Add pages",
+			want:    []Violation{{Rule: RuleTrailerExists, Level: config.LevelWarn, Message: `message must have a "Signed-off-by" trailer`}},
+		},
+		{
+			name:    "skip ci marker is not part of the body",
+			rules:   map[string]config.RuleConfig{RuleBodyMaxLineLength: {Options: map[string]interface{}{"max": 5.0}}},
+			message: "docs: Update the readme\n\n[skip ci]",
+		},
+		{name: "merge commit", message: "Merge branch 'main' into feature"},
+		{name: "revert commit", message: "Revert \"feat: Add pages\""},
+		{name: "fixup commit", message: "fixup! feat: Add pages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefault()
+			cfg.Rules = tt.rules
+
+			got := Lint(tt.message, cfg)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		violations []Violation
+		want       bool
+	}{
+		{name: "none"},
+		{name: "warnings only", violations: []Violation{{Level: config.LevelWarn}, {Level: config.LevelWarn}}},
+		{name: "an error", violations: []Violation{{Level: config.LevelWarn}, {Level: config.LevelError}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasErrors(tt.violations); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Package lint provides functionality for validating commit messages against the CommitSense configuration.
+
+This file includes the lint rules, their default levels and options, and the checks they run on commit messages.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package lint
+
+import (
+	"commitsense/pkg/commit"
+	"commitsense/pkg/config"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule names reported in violations.
+const (
+	RuleHeaderFormat       = "header-format"
+	RuleHeaderMaxLength    = "header-max-length"
+	RuleTypeEnum           = "type-enum"
+	RuleScopeEnum          = "scope-enum"
+	RuleScopeEmpty         = "scope-empty"
+	RuleScopeAllowed       = "scope-allowed"
+	RuleScopeCase          = "scope-case"
+	RuleSubjectCase        = "subject-case"
+	RuleSubjectFullStop    = "subject-full-stop"
+	RuleSubjectForbidden   = "subject-forbidden-words"
+	RuleBodyLeadingBlank   = "body-leading-blank"
+	RuleBodyMaxLineLength  = "body-max-line-length"
+	RuleFooterLeadingBlank = "footer-leading-blank"
+	RuleTrailerExists      = "trailer-exists"
+)
+
+// Cases accepted by the case option of the subject-case and scope-case rules.
+var Cases = []string{"lower-case", "upper-case", "sentence-case", "camel-case", "pascal-case", "kebab-case", "snake-case"}
+
+var casePatterns = map[string]*regexp.Regexp{
+	"camel-case":  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"pascal-case": regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	"kebab-case":  regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`),
+	"snake-case":  regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`),
+}
+
+// Options are the options of a rule, decoded from JSON.
+type Options map[string]interface{}
+
+// Rule is a named check of commit messages. A rule runs at its default level with its default options
+// unless it is configured otherwise in the rules of the configuration. The header-format rule, which
+// checks that the message can be parsed at all, is not listed as it always runs at the error level.
+type Rule struct {
+	Name string
+	// Level is the default level of the rule.
+	Level string
+	// Options are the default options of the rule. The options of a rule can only be set to values of the
+	// same type as their defaults.
+	Options Options
+
+	check func(m *message, options Options) []string
+}
+
+// Rules lists the rules in the order they are checked.
+var Rules = []Rule{
+	{Name: RuleHeaderMaxLength, Level: config.LevelError, Options: Options{"max": 72.0}, check: checkHeaderMaxLength},
+	{Name: RuleTypeEnum, Level: config.LevelError, check: checkTypeEnum},
+	{Name: RuleScopeEnum, Level: config.LevelError, check: scopeCheck(config.ErrUnknownScope)},
+	{Name: RuleScopeEmpty, Level: config.LevelError, check: scopeCheck(config.ErrScopeRequired)},
+	{Name: RuleScopeAllowed, Level: config.LevelError, check: scopeCheck(config.ErrScopeForbidden)},
+	{Name: RuleScopeCase, Level: config.LevelError, Options: Options{"case": "lower-case", "pattern": ""}, check: checkScopeCase},
+	{Name: RuleSubjectCase, Level: config.LevelError, Options: Options{"case": "sentence-case", "pattern": ""}, check: checkSubjectCase},
+	{Name: RuleSubjectFullStop, Level: config.LevelError, Options: Options{"char": "."}, check: checkSubjectFullStop},
+	{Name: RuleSubjectForbidden, Level: config.LevelError, Options: Options{"words": []interface{}{}}, check: checkSubjectForbidden},
+	{Name: RuleBodyLeadingBlank, Level: config.LevelWarn, check: checkBodyLeadingBlank},
+	{Name: RuleBodyMaxLineLength, Level: config.LevelError, Options: Options{"max": 100.0}, check: checkBodyMaxLineLength},
+	{Name: RuleFooterLeadingBlank, Level: config.LevelWarn, check: checkFooterLeadingBlank},
+	{Name: RuleTrailerExists, Level: config.LevelOff, Options: Options{"trailer": "Signed-off-by"}, check: checkTrailerExists},
+}
+
+// LookupRule finds a rule by its name.
+func LookupRule(name string) (Rule, bool) {
+	for _, rule := range Rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Configure returns the level and the options of the rule in the configuration.
+func (r Rule) Configure(cfg *config.Config) (string, Options) {
+	ruleConfig, ok := cfg.Rules[r.Name]
+	if !ok {
+		return r.Level, r.Options
+	}
+
+	level := r.Level
+	if ruleConfig.Level != "" {
+		level = ruleConfig.Level
+	}
+
+	options := Options{}
+	for key, value := range r.Options {
+		options[key] = value
+	}
+	for key, value := range ruleConfig.Options {
+		options[key] = value
+	}
+
+	return level, options
+}
+
+// Int returns the number option with the key, or 0 when it is not a number.
+func (o Options) Int(key string) int {
+	n, _ := o[key].(float64)
+	return int(n)
+}
+
+// String returns the string option with the key, or an empty string when it is not a string.
+func (o Options) String(key string) string {
+	s, _ := o[key].(string)
+	return s
+}
+
+// Strings returns the string items of the list option with the key.
+func (o Options) Strings(key string) []string {
+	items, _ := o[key].([]interface{})
+
+	var strs []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+
+	return strs
+}
+
+func checkHeaderMaxLength(m *message, options Options) []string {
+	max := options.Int("max")
+	if length := utf8.RuneCountInString(m.lines[0]); length > max {
+		return []string{fmt.Sprintf("header must not be longer than %d characters, got %d", max, length)}
+	}
+	return nil
+}
+
+func checkTypeEnum(m *message, _ Options) []string {
+	if _, ok := m.cfg.LookupType(m.commit.CommitType); !ok {
+		return []string{fmt.Sprintf("commit type %q must be one of [%s]", m.commit.CommitType, strings.Join(m.cfg.TypeNames(), ", "))}
+	}
+	return nil
+}
+
+// scopeCheck returns a check reporting the scope validation errors of the given kind.
+func scopeCheck(target error) func(m *message, options Options) []string {
+	return func(m *message, _ Options) []string {
+		if err := m.cfg.ValidateScope(m.commit.CommitType, m.commit.CommitScope); errors.Is(err, target) {
+			return []string{err.Error()}
+		}
+		return nil
+	}
+}
+
+func checkScopeCase(m *message, options Options) []string {
+	if m.commit.CommitScope == "" {
+		return nil
+	}
+	if problem := checkCase(m.commit.CommitScope, options); problem != "" {
+		return []string{fmt.Sprintf("scope %q must %s", m.commit.CommitScope, problem)}
+	}
+	return nil
+}
+
+func checkSubjectCase(m *message, options Options) []string {
+	if problem := checkCase(m.commit.CommitDescription, options); problem != "" {
+		return []string{"subject must " + problem}
+	}
+	return nil
+}
+
+// checkCase checks the text against the pattern option, or the case option when there is no pattern,
+// and describes what the text must be like when it does not match.
+func checkCase(s string, options Options) string {
+	if pattern := options.String("pattern"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err == nil && !re.MatchString(s) {
+			return fmt.Sprintf("match the pattern %q", pattern)
+		}
+		return ""
+	}
+
+	if c := options.String("case"); !matchesCase(s, c) {
+		return "be in " + c
+	}
+	return ""
+}
+
+// matchesCase reports whether the text is in the given case. Only the first letter of the text is checked
+// for sentence-case, so that the rest of the sentence can contain names and abbreviations.
+func matchesCase(s string, c string) bool {
+	switch c {
+	case "lower-case":
+		return s == strings.ToLower(s)
+	case "upper-case":
+		return s == strings.ToUpper(s)
+	case "sentence-case":
+		first, _ := utf8.DecodeRuneInString(s)
+		return !unicode.IsLower(first)
+	default:
+		if pattern, ok := casePatterns[c]; ok {
+			return pattern.MatchString(s)
+		}
+		return true
+	}
+}
+
+func checkSubjectFullStop(m *message, options Options) []string {
+	if char := options.String("char"); char != "" && strings.HasSuffix(m.commit.CommitDescription, char) {
+		return []string{fmt.Sprintf("subject must not end with %q", char)}
+	}
+	return nil
+}
+
+// checkSubjectForbidden reports the forbidden words found in the subject, matching whole words regardless
+// of their case.
+func checkSubjectForbidden(m *message, options Options) []string {
+	var problems []string
+	for _, word := range options.Strings("words") {
+		pattern := regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(word) + `(\W|$)`)
+		if word != "" && pattern.MatchString(m.commit.CommitDescription) {
+			problems = append(problems, fmt.Sprintf("subject must not contain the word %q", word))
+		}
+	}
+	return problems
+}
+
+func checkBodyLeadingBlank(m *message, _ Options) []string {
+	if len(m.lines) > 1 && m.lines[1] != "" {
+		return []string{"body must be separated from the header by a blank line"}
+	}
+	return nil
+}
+
+func checkBodyMaxLineLength(m *message, options Options) []string {
+	if m.commit.CommitBody == "" {
+		return nil
+	}
+
+	max := options.Int("max")
+
+	var problems []string
+	for i, line := range strings.Split(m.commit.CommitBody, "\n") {
+		if length := utf8.RuneCountInString(line); length > max {
+			problems = append(problems, fmt.Sprintf("body line %d must not be longer than %d characters, got %d", i+1, max, length))
+		}
+	}
+
+	return problems
+}
+
+// checkFooterLeadingBlank reports trailers at the end of the message that are not separated from the
+// body by a blank line, which makes them part of the body.
+func checkFooterLeadingBlank(m *message, _ Options) []string {
+	start := len(m.lines)
+	for start > 1 && m.lines[start-1] != "" {
+		start--
+	}
+	if start == 1 || start == len(m.lines) || commit.IsFooter(m.lines[start]) {
+		return nil
+	}
+
+	for _, line := range m.lines[start+1:] {
+		if commit.IsFooter(line) {
+			return []string{"footer must be separated from the body by a blank line"}
+		}
+	}
+
+	return nil
+}
+
+func checkTrailerExists(m *message, options Options) []string {
+	trailer := options.String("trailer")
+
+	tokens := make([]string, 0, len(m.commit.Footers)+2)
+	for _, footer := range m.commit.Footers {
+		tokens = append(tokens, footer.Token)
+	}
+	if m.commit.IsCoAuthored {
+		tokens = append(tokens, "Co-authored-by")
+	}
+	if m.commit.BreakingChangeDescription != "" {
+		tokens = append(tokens, "BREAKING CHANGE")
+	}
+
+	for _, token := range tokens {
+		if strings.EqualFold(token, trailer) {
+			return nil
+		}
+	}
+
+	return []string{fmt.Sprintf("message must have a %q trailer", trailer)}
+}
@@ -0,0 +1,363 @@
+package lint
+
+import (
+	"commitsense/pkg/config"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ruleViolations lints the message and returns the messages of the violations of the rule.
+func ruleViolations(cfg *config.Config, rule string, msg string) []string {
+	var messages []string
+	for _, violation := range Lint(msg, cfg) {
+		if violation.Rule == rule {
+			messages = append(messages, violation.Message)
+		}
+	}
+	return messages
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		options map[string]interface{}
+		message string
+		want    []string
+	}{
+		{name: "header within the default max", rule: RuleHeaderMaxLength, message: "feat: " + strings.Repeat("a", 66)},
+		{
+			name:    "header over the default max",
+			rule:    RuleHeaderMaxLength,
+			message: "feat: " + strings.Repeat("a", 67),
+			want:    []string{"header must not be longer than 72 characters, got 73"},
+		},
+		{
+			name:    "header over a configured max",
+			rule:    RuleHeaderMaxLength,
+			options: map[string]interface{}{"max": 20.0},
+			message: "feat: Add the login page",
+			want:    []string{"header must not be longer than 20 characters, got 24"},
+		},
+		{name: "header length counts characters", rule: RuleHeaderMaxLength, message: "feat: " + strings.Repeat("ä", 66)},
+
+		{name: "known type", rule: RuleTypeEnum, message: "feat: Add pages"},
+		{
+			name:    "unknown type",
+			rule:    RuleTypeEnum,
+			message: "feature: Add pages",
+			want:    []string{`commit type "feature" must be one of [feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert]`},
+		},
+
+		{name: "configured scope", rule: RuleScopeEnum, message: "fix(api): Handle errors"},
+		{
+			name:    "unknown scope",
+			rule:    RuleScopeEnum,
+			message: "fix(web): Handle errors",
+			want:    []string{`scope is not configured: "web" must be one of [api]`},
+		},
+		{name: "required scope given", rule: RuleScopeEmpty, message: "feat(api): Add pages"},
+		{
+			name:    "required scope missing",
+			rule:    RuleScopeEmpty,
+			message: "feat: Add pages",
+			want:    []string{`scope is required for commit type "feat"`},
+		},
+		{name: "forbidden scope missing", rule: RuleScopeAllowed, message: "docs: Update the readme"},
+		{
+			name:    "forbidden scope given",
+			rule:    RuleScopeAllowed,
+			message: "docs(api): Update the readme",
+			want:    []string{`scope is not allowed for commit type "docs"`},
+		},
+
+		{name: "scope in the default case", rule: RuleScopeCase, message: "fix(api): Handle errors"},
+		{name: "no scope", rule: RuleScopeCase, message: "fix: Handle errors"},
+		{
+			name:    "scope not in the default case",
+			rule:    RuleScopeCase,
+			message: "fix(Api): Handle errors",
+			want:    []string{`scope "Api" must be in lower-case`},
+		},
+		{
+			name:    "scope in a configured case",
+			rule:    RuleScopeCase,
+			options: map[string]interface{}{"case": "kebab-case"},
+			message: "fix(user-api): Handle errors",
+		},
+		{
+			name:    "scope not in a configured case",
+			rule:    RuleScopeCase,
+			options: map[string]interface{}{"case": "kebab-case"},
+			message: "fix(user_api): Handle errors",
+			want:    []string{`scope "user_api" must be in kebab-case`},
+		},
+		{
+			name:    "scope not matching a configured pattern",
+			rule:    RuleScopeCase,
+			options: map[string]interface{}{"pattern": "^[a-z]+$"},
+			message: "fix(api2): Handle errors",
+			want:    []string{`scope "api2" must match the pattern "^[a-z]+$"`},
+		},
+
+		{name: "subject in the default case", rule: RuleSubjectCase, message: "feat: Add the API"},
+		{name: "subject starting with a digit", rule: RuleSubjectCase, message: "feat: 2 new pages"},
+		{
+			name:    "subject not in the default case",
+			rule:    RuleSubjectCase,
+			message: "feat: add pages",
+			want:    []string{"subject must be in sentence-case"},
+		},
+		{
+			name:    "subject in a configured case",
+			rule:    RuleSubjectCase,
+			options: map[string]interface{}{"case": "lower-case"},
+			message: "feat: add pages",
+		},
+		{
+			name:    "subject not in a configured case",
+			rule:    RuleSubjectCase,
+			options: map[string]interface{}{"case": "upper-case"},
+			message: "feat: Add pages",
+			want:    []string{"subject must be in upper-case"},
+		},
+		{
+			name:    "subject matching a configured pattern",
+			rule:    RuleSubjectCase,
+			options: map[string]interface{}{"pattern": "^[A-Z]+-[0-9]+ "},
+			message: "feat: WEB-12 add pages",
+		},
+		{
+			name:    "subject not matching a configured pattern",
+			rule:    RuleSubjectCase,
+			options: map[string]interface{}{"pattern": "^[A-Z]+-[0-9]+ "},
+			message: "feat: Add pages",
+			want:    []string{`subject must match the pattern "^[A-Z]+-[0-9]+ "`},
+		},
+
+		{name: "subject without a full stop", rule: RuleSubjectFullStop, message: "fix: Handle errors"},
+		{
+			name:    "subject with the default full stop",
+			rule:    RuleSubjectFullStop,
+			message: "fix: Handle errors.",
+			want:    []string{`subject must not end with "."`},
+		},
+		{
+			name:    "subject with a configured full stop",
+			rule:    RuleSubjectFullStop,
+			options: map[string]interface{}{"char": "!"},
+			message: "fix: Handle errors!",
+			want:    []string{`subject must not end with "!"`},
+		},
+		{
+			name:    "full stop check turned off with an empty char",
+			rule:    RuleSubjectFullStop,
+			options: map[string]interface{}{"char": ""},
+			message: "fix: Handle errors.",
+		},
+
+		{name: "no forbidden words by default", rule: RuleSubjectForbidden, message: "fix: Fix stuff"},
+		{
+			name:    "forbidden words",
+			rule:    RuleSubjectForbidden,
+			options: map[string]interface{}{"words": []interface{}{"stuff", "wip"}},
+			message: "fix: WIP on stuff",
+			want:    []string{`subject must not contain the word "stuff"`, `subject must not contain the word "wip"`},
+		},
+		{
+			name:    "forbidden words match whole words only",
+			rule:    RuleSubjectForbidden,
+			options: map[string]interface{}{"words": []interface{}{"wip"}},
+			message: "fix: Handle wiping the cache",
+		},
+
+		{name: "body after a blank line", rule: RuleBodyLeadingBlank, message: "fix: Handle errors\n\nThe body."},
+		{
+			name:    "body right after the header",
+			rule:    RuleBodyLeadingBlank,
+			message: "fix: Handle errors\nThe body.",
+			want:    []string{"body must be separated from the header by a blank line"},
+		},
+
+		{name: "body lines within the default max", rule: RuleBodyMaxLineLength, message: "fix: Handle errors\n\n" + strings.Repeat("a", 100)},
+		{
+			name:    "body line over the default max",
+			rule:    RuleBodyMaxLineLength,
+			message: "fix: Handle errors\n\nThe body.\n" + strings.Repeat("a", 101),
+			want:    []string{"body line 2 must not be longer than 100 characters, got 101"},
+		},
+		{
+			name:    "body lines over a configured max",
+			rule:    RuleBodyMaxLineLength,
+			options: map[string]interface{}{"max": 10.0},
+			message: "fix: Handle errors\n\nThe first line.\nShort.\nThe third line.",
+			want: []string{
+				"body line 1 must not be longer than 10 characters, got 15",
+				"body line 3 must not be longer than 10 characters, got 15",
+			},
+		},
+
+		{name: "footer after a blank line", rule: RuleFooterLeadingBlank, message: "fix: Handle errors\n\nThe body.\n\nRefs: #1"},
+		{name: "body line looking like a trailer", rule: RuleFooterLeadingBlank, message: "fix: Handle errors\n\nNote: the body."},
+		{
+			name:    "footer right after the body",
+			rule:    RuleFooterLeadingBlank,
+			message: "fix: Handle errors\n\nThe body.\nRefs: #1",
+			want:    []string{"footer must be separated from the body by a blank line"},
+		},
+
+		{name: "trailer check off by default", rule: RuleTrailerExists, message: "fix: Handle errors"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefault()
+			cfg.Scopes = []config.Scope{{Name: "api"}}
+			cfg.EnforceScopes = true
+			cfg.ScopeModes = map[string]string{"feat": config.ScopeRequired, "docs": config.ScopeForbidden}
+			if tt.options != nil {
+				cfg.Rules = map[string]config.RuleConfig{tt.rule: {Options: tt.options}}
+			}
+
+			got := ruleViolations(cfg, tt.rule, tt.message)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrailerExists(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		message string
+		want    []string
+	}{
+		{
+			name:    "default trailer present",
+			message: "fix: Handle errors\n\nSigned-off-by: Jane Doe <jane@example.com>",
+		},
+		{
+			name:    "default trailer in another case",
+			message: "fix: Handle errors\n\nsigned-off-by: Jane Doe <jane@example.com>",
+		},
+		{
+			name:    "default trailer missing",
+			message: "fix: Handle errors\n\nRefs: #1",
+			want:    []string{`message must have a "Signed-off-by" trailer`},
+		},
+		{
+			name:    "configured co-author trailer",
+			options: map[string]interface{}{"trailer": "Co-authored-by"},
+			message: "fix: Handle errors\n\nCo-authored-by: Jane Doe <jane@example.com>",
+		},
+		{
+			name:    "configured breaking change trailer",
+			options: map[string]interface{}{"trailer": "BREAKING CHANGE"},
+			message: "fix!: Handle errors\n\nBREAKING CHANGE: errors are returned",
+		},
+		{
+			name:    "configured trailer missing",
+			options: map[string]interface{}{"trailer": "Refs"},
+			message: "fix: Handle errors",
+			want:    []string{`message must have a "Refs" trailer`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefault()
+			cfg.Rules = map[string]config.RuleConfig{RuleTrailerExists: {Level: config.LevelError, Options: tt.options}}
+
+			got := ruleViolations(cfg, RuleTrailerExists, tt.message)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesCase(t *testing.T) {
+	tests := []struct {
+		text string
+		c    string
+		want bool
+	}{
+		{text: "add pages", c: "lower-case", want: true},
+		{text: "Add pages", c: "lower-case"},
+		{text: "ADD PAGES", c: "upper-case", want: true},
+		{text: "Add pages", c: "upper-case"},
+		{text: "Add the API", c: "sentence-case", want: true},
+		{text: "Äänet", c: "sentence-case", want: true},
+		{text: "add pages", c: "sentence-case"},
+		{text: "userApi", c: "camel-case", want: true},
+		{text: "UserApi", c: "camel-case"},
+		{text: "UserApi", c: "pascal-case", want: true},
+		{text: "userApi", c: "pascal-case"},
+		{text: "user-api", c: "kebab-case", want: true},
+		{text: "user_api", c: "kebab-case"},
+		{text: "user_api", c: "snake-case", want: true},
+		{text: "user-api", c: "snake-case"},
+		{text: "anything", c: "unknown-case", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.c+" "+tt.text, func(t *testing.T) {
+			if got := matchesCase(tt.text, tt.c); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	rule, _ := LookupRule(RuleHeaderMaxLength)
+
+	tests := []struct {
+		name        string
+		rules       map[string]config.RuleConfig
+		wantLevel   string
+		wantOptions Options
+	}{
+		{name: "not configured", wantLevel: config.LevelError, wantOptions: Options{"max": 72.0}},
+		{
+			name:        "level only",
+			rules:       map[string]config.RuleConfig{RuleHeaderMaxLength: {Level: config.LevelWarn}},
+			wantLevel:   config.LevelWarn,
+			wantOptions: Options{"max": 72.0},
+		},
+		{
+			name:        "options only",
+			rules:       map[string]config.RuleConfig{RuleHeaderMaxLength: {Options: map[string]interface{}{"max": 50.0}}},
+			wantLevel:   config.LevelError,
+			wantOptions: Options{"max": 50.0},
+		},
+		{
+			name:        "other rule configured",
+			rules:       map[string]config.RuleConfig{RuleTypeEnum: {Level: config.LevelOff}},
+			wantLevel:   config.LevelError,
+			wantOptions: Options{"max": 72.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefault()
+			cfg.Rules = tt.rules
+
+			level, options := rule.Configure(cfg)
+			if level != tt.wantLevel {
+				t.Errorf("got level %q, want %q", level, tt.wantLevel)
+			}
+			if !reflect.DeepEqual(options, tt.wantOptions) {
+				t.Errorf("got options %v, want %v", options, tt.wantOptions)
+			}
+		})
+	}
+
+	if rule.Options["max"] != 72.0 {
+		t.Errorf("configuring the rule changed its default options to %v", rule.Options)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Package lint provides functionality for validating commit messages against the CommitSense configuration.
+
+This file includes the validation of the rules in the configuration, which the JSON Schema of the
+configuration file cannot check on its own.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package lint
+
+import (
+	"commitsense/pkg/config"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ValidateRules checks the rules of a configuration for unknown rule names, unknown options, options of the
+// wrong type, unknown cases and malformed regular expressions.
+func ValidateRules(rules map[string]config.RuleConfig) []config.Problem {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []config.Problem
+	for _, name := range names {
+		pointer := "/rules/" + name
+
+		rule, ok := LookupRule(name)
+		if !ok {
+			problems = append(problems, config.Problem{
+				Pointer: pointer,
+				Message: fmt.Sprintf("unknown rule %q", name),
+			})
+			continue
+		}
+
+		problems = append(problems, validateOptions(rule, rules[name].Options, pointer+"/options")...)
+	}
+
+	return problems
+}
+
+func validateOptions(rule Rule, options map[string]interface{}, pointer string) []config.Problem {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []config.Problem
+	for _, key := range keys {
+		value := options[key]
+		problem := config.Problem{Pointer: pointer + "/" + key}
+
+		defaultValue, ok := rule.Options[key]
+		switch {
+		case !ok:
+			problem.Message = fmt.Sprintf("unknown option %q of rule %q", key, rule.Name)
+		case typeName(value) != typeName(defaultValue):
+			problem.Message = "must be of type " + typeName(defaultValue)
+		case key == "case" && !isCase(value.(string)):
+			problem.Message = fmt.Sprintf("must be one of [%s], got %q", strings.Join(Cases, ", "), value)
+		case key == "words" && len(Options(options).Strings(key)) != len(value.([]interface{})):
+			problem.Message = "must be a list of strings"
+		case key == "pattern":
+			if _, err := regexp.Compile(value.(string)); err != nil {
+				problem.Message = fmt.Sprintf("malformed regular expression: %v", err)
+			}
+		}
+
+		if problem.Message != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+func isCase(c string) bool {
+	for _, known := range Cases {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the JSON Schema type name of a decoded JSON value.
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,86 @@
+package lint
+
+import (
+	"commitsense/pkg/config"
+	"reflect"
+	"testing"
+)
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules map[string]config.RuleConfig
+		want  []config.Problem
+	}{
+		{name: "no rules"},
+		{
+			name: "valid rules",
+			rules: map[string]config.RuleConfig{
+				RuleHeaderMaxLength:  {Level: config.LevelWarn, Options: map[string]interface{}{"max": 50.0}},
+				RuleSubjectCase:      {Options: map[string]interface{}{"case": "lower-case", "pattern": "^[a-z]"}},
+				RuleSubjectForbidden: {Options: map[string]interface{}{"words": []interface{}{"wip"}}},
+				RuleTypeEnum:         {Level: config.LevelOff},
+			},
+		},
+		{
+			name:  "unknown rule",
+			rules: map[string]config.RuleConfig{"header-min-length": {Level: config.LevelError}},
+			want:  []config.Problem{{Pointer: "/rules/header-min-length", Message: `unknown rule "header-min-length"`}},
+		},
+		{
+			name:  "unknown option",
+			rules: map[string]config.RuleConfig{RuleHeaderMaxLength: {Options: map[string]interface{}{"min": 10.0}}},
+			want: []config.Problem{{
+				Pointer: "/rules/header-max-length/options/min",
+				Message: `unknown option "min" of rule "header-max-length"`,
+			}},
+		},
+		{
+			name:  "option of the wrong type",
+			rules: map[string]config.RuleConfig{RuleHeaderMaxLength: {Options: map[string]interface{}{"max": "50"}}},
+			want:  []config.Problem{{Pointer: "/rules/header-max-length/options/max", Message: "must be of type number"}},
+		},
+		{
+			name:  "unknown case",
+			rules: map[string]config.RuleConfig{RuleScopeCase: {Options: map[string]interface{}{"case": "title-case"}}},
+			want: []config.Problem{{
+				Pointer: "/rules/scope-case/options/case",
+				Message: `must be one of [lower-case, upper-case, sentence-case, camel-case, pascal-case, kebab-case, snake-case], got "title-case"`,
+			}},
+		},
+		{
+			name:  "words that are not strings",
+			rules: map[string]config.RuleConfig{RuleSubjectForbidden: {Options: map[string]interface{}{"words": []interface{}{"wip", 1.0}}}},
+			want:  []config.Problem{{Pointer: "/rules/subject-forbidden-words/options/words", Message: "must be a list of strings"}},
+		},
+		{
+			name:  "malformed pattern",
+			rules: map[string]config.RuleConfig{RuleSubjectCase: {Options: map[string]interface{}{"pattern": "[a-z"}}},
+			want: []config.Problem{{
+				Pointer: "/rules/subject-case/options/pattern",
+				Message: "malformed regular expression: error parsing regexp: missing closing ]: `[a-z`",
+			}},
+		},
+		{
+			name: "problems sorted by rule and option",
+			rules: map[string]config.RuleConfig{
+				RuleSubjectCase:     {Options: map[string]interface{}{"pattern": 1.0, "case": true}},
+				RuleHeaderMaxLength: {Options: map[string]interface{}{"max": false}},
+			},
+			want: []config.Problem{
+				{Pointer: "/rules/header-max-length/options/max", Message: "must be of type number"},
+				{Pointer: "/rules/subject-case/options/case", Message: "must be of type string"},
+				{Pointer: "/rules/subject-case/options/pattern", Message: "must be of type string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateRules(tt.rules)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
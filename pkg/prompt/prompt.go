@@ -16,6 +16,7 @@ package prompt
 import (
 	"commitsense/pkg/author"
 	"commitsense/pkg/config"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -24,45 +25,249 @@ import (
 	"github.com/manifoldco/promptui"
 )
 
+const multiSelectSize = 10
+
+// Feedback is shown next to the label of a prompt while the user types, see LiveString.
+type Feedback struct {
+	// Counter is the length of the input against its limit, e.g. "23/72".
+	Counter string
+	// Errors are the problems that prevent the input from being accepted.
+	Errors []string
+	// Warnings are the problems that do not prevent the input from being accepted.
+	Warnings []string
+}
+
+// feedbackLabel is the label of a prompt that shows feedback on the input.
+type feedbackLabel struct {
+	Text     string
+	Feedback Feedback
+}
+
 // Item represents an item with an ID referring to a certain item in a multiselect prompt
 type Item struct {
-	ID         string
-	IsSelected bool
+	ID          string
+	IsSelected  bool
+	Description string
+	// Details returns the text shown below the list when the item is active, e.g. a diff preview.
+	// It is called lazily and the result is cached.
+	Details func() string
+
+	details *string
+}
+
+var (
+	continueItem  = &Item{ID: "Continue"}
+	selectAllItem = &Item{ID: "Select all"}
+)
+
+// DetailsText returns the cached details of the item, used by the select templates.
+func (i *Item) DetailsText() string {
+	if i.Details == nil {
+		return ""
+	}
+	if i.details == nil {
+		details := i.Details()
+		i.details = &details
+	}
+	return *i.details
 }
 
-// CommitType prompts the user to select a commit type.
+// CommitType prompts the user to select a commit type. Hidden commit types are not offered.
 func CommitType(label string) (string, error) {
-	cfg, _ := config.Read()
+	cfg, err := config.Read()
+	if err != nil {
+		return "", err
+	}
 
-	if len(cfg.CommitTypes) == 0 {
-		return "", fmt.Errorf("no commit types found in the configuration file. Please add commit types to the configuration file")
+	commitTypes := cfg.VisibleTypes()
+	if len(commitTypes) == 0 {
+		return "", errors.New("all of the commit types are hidden, give the commit type with --type")
 	}
 
 	promptType := promptui.Select{
 		Label: label,
-		Items: cfg.CommitTypes,
+		Items: commitTypes,
+		Templates: &promptui.SelectTemplates{
+			Label:    "{{ . }}",
+			Active:   "▸ {{ with .Emoji }}{{ . }} {{ end }}{{ .Name | underline }} {{ .Summary | faint }}",
+			Inactive: "  {{ with .Emoji }}{{ . }} {{ end }}{{ .Name }} {{ .Summary | faint }}",
+			Selected: "{{ \"Commit type:\" | faint }} {{ .Name }}",
+		},
+		Size: multiSelectSize,
+	}
+
+	index, _, err := promptType.Run()
+	if err != nil {
+		return "", err
+	}
+
+	return commitTypes[index].Name, nil
+}
+
+// Scope prompts the user for the scope of a commit of the given type.
+//
+// If scopes are configured, the user selects one of them, or no scope when the scope is optional for the
+// commit type. Unless the scopes are enforced, a custom scope can be entered as well. Without configured
+// scopes the scope is entered as free text. No prompt is shown when the commit type forbids a scope.
+// The suggested scope, if any, is selected or filled in initially.
+func Scope(label string, commitType string, suggested string) (string, error) {
+	cfg, err := config.Read()
+	if err != nil {
+		return "", err
+	}
+
+	mode := cfg.ScopeMode(commitType)
+	if mode == config.ScopeForbidden {
+		return "", nil
+	}
+
+	validateScope := func(scope string) error {
+		return cfg.ValidateScope(commitType, scope)
+	}
+
+	promptCustomScope := func() (string, error) {
+		promptString := promptui.Prompt{
+			Label:     label,
+			Validate:  validateScope,
+			Default:   suggested,
+			AllowEdit: true,
+		}
+		return promptString.Run()
+	}
+
+	if len(cfg.Scopes) == 0 {
+		return promptCustomScope()
+	}
+
+	items, cursorPos := scopeItems(cfg, mode, suggested)
+
+	promptScope := promptui.Select{
+		Label: label,
+		Items: items,
+		Templates: &promptui.SelectTemplates{
+			Label:    "{{ . }}",
+			Active:   "▸ {{ .Name | underline }} {{ .Description | faint }}",
+			Inactive: "  {{ .Name }} {{ .Description | faint }}",
+			Selected: "{{ \"Scope:\" | faint }} {{ .Name }}",
+		},
+		Size: multiSelectSize,
+	}
+
+	index, _, err := promptScope.RunCursorAt(cursorPos, cursorPos-multiSelectSize+1)
+	if err != nil {
+		return "", err
 	}
 
-	_, typeResult, err := promptType.Run()
+	if !cfg.EnforceScopes && index == len(items)-1 {
+		return promptCustomScope()
+	}
 
-	return typeResult, err
+	return items[index].Name, nil
 }
 
-// String prompts the user to enter a string.
-func String(label string, validator promptui.ValidateFunc) (string, error) {
+// scopeItems returns the items of the scope selection and the position of the suggested scope among them.
+func scopeItems(cfg *config.Config, mode string, suggested string) ([]config.Scope, int) {
+	noScope := config.Scope{Description: "no scope"}
+	customScope := config.Scope{Description: "enter a custom scope"}
+
+	var items []config.Scope
+	if mode != config.ScopeRequired {
+		items = append(items, noScope)
+	}
+	items = append(items, cfg.Scopes...)
+	if !cfg.EnforceScopes {
+		items = append(items, customScope)
+	}
+
+	cursorPos := 0
+	if suggested != "" {
+		if !cfg.EnforceScopes {
+			// A suggestion that is not configured is filled into the custom scope prompt.
+			cursorPos = len(items) - 1
+		}
+		for i, item := range items {
+			if item.Name == suggested {
+				cursorPos = i
+			}
+		}
+	}
+
+	return items, cursorPos
+}
+
+// Select prompts the user to select one of the items and returns the index of the selected item.
+func Select(label string, items []string) (int, error) {
+	promptSelect := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+
+	index, _, err := promptSelect.Run()
+
+	return index, err
+}
+
+// Confirm prompts the user with a yes or no question.
+func Confirm(label string, defaultYes bool) (bool, error) {
+	cursor := 1
+	if defaultYes {
+		cursor = 0
+	}
+
+	promptSelect := promptui.Select{
+		Label:     label,
+		Items:     []string{"Yes", "No"},
+		CursorPos: cursor,
+	}
+
+	index, _, err := promptSelect.Run()
+
+	return index == 0, err
+}
+
+// String prompts the user to enter a string. The input is accepted once it passes all the validators.
+func String(label string, validators ...promptui.ValidateFunc) (string, error) {
+	return LiveString(label, nil, validators...)
+}
+
+// LiveString prompts the user to enter a string like String, showing the feedback on the input next to
+// the label while the user types. The feedback function may be nil.
+func LiveString(label string, feedback func(input string) Feedback, validators ...promptui.ValidateFunc) (string, error) {
+	live := &feedbackLabel{Text: label}
+
 	promptString := promptui.Prompt{
-		Label:    label,
-		Validate: validator,
+		Label: live,
+		Validate: func(input string) error {
+			// promptui validates the input on every keystroke before it renders the label,
+			// which keeps the feedback up to date.
+			if feedback != nil {
+				live.Feedback = feedback(input)
+			}
+
+			for _, validator := range validators {
+				if validator == nil {
+					continue
+				}
+				if err := validator(input); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		Templates: createFeedbackTemplates(),
 	}
+
 	return promptString.Run()
 }
 
 // MultilineString prompts the user for a multiline string input based on the provided prompt configuration.
-// Users can enter multiple lines of text until they press Enter twice to finish.
-func MultilineString(label string) (string, error) {
+// Users can enter multiple lines of text until they press Enter twice to finish. The feedback and the
+// validators apply to each line.
+func MultilineString(label string, feedback func(line string) Feedback, validators ...promptui.ValidateFunc) (string, error) {
 	var lines []string
 	for {
-		line, err := String(label, nil)
+		line, err := LiveString(label, feedback, validators...)
 		if err != nil || line == "" {
 			break
 		}
@@ -106,19 +311,80 @@ func CoAuthors(label string) ([]string, error) {
 	return coAuthors, nil
 }
 
+// MultiSelect prompts the user to select any number of the items.
+//
+// Choosing an item toggles its selection, the "Select all" option selects or deselects every item
+// and the "Continue" option finishes the prompt. The items are modified in place and the selected
+// items are returned.
+func MultiSelect(label string, items []*Item) ([]*Item, error) {
+	options := prependItemsWithSpecialOptions(items)
+	cursorPos := 0
+
+	for {
+		promptSelect := promptui.Select{
+			Label:        label,
+			Items:        options,
+			Templates:    createSelectTemplates(),
+			Size:         multiSelectSize,
+			HideSelected: true,
+		}
+
+		index, _, err := promptSelect.RunCursorAt(cursorPos, cursorPos-multiSelectSize+1)
+		if err != nil {
+			return nil, err
+		}
+		cursorPos = index
+
+		switch options[index] {
+		case continueItem:
+			var selected []*Item
+			for _, item := range items {
+				if item.IsSelected {
+					selected = append(selected, item)
+				}
+			}
+			return selected, nil
+		case selectAllItem:
+			allSelected := true
+			for _, item := range items {
+				allSelected = allSelected && item.IsSelected
+			}
+			for _, item := range items {
+				item.IsSelected = !allSelected
+			}
+		default:
+			options[index].IsSelected = !options[index].IsSelected
+		}
+	}
+}
+
+func createFeedbackTemplates() *promptui.PromptTemplates {
+	bold := promptui.Styler(promptui.FGBold)
+	label := `{{ .Text | bold }}` +
+		`{{ with .Feedback.Counter }} {{ printf "(%s)" . | faint }}{{ end }}` +
+		`{{ range .Feedback.Errors }} {{ "✖" | red }} {{ . | red }}{{ end }}` +
+		`{{ range .Feedback.Warnings }} {{ "⚠" | yellow }} {{ . | yellow }}{{ end }}` +
+		`{{ ":" | bold }} `
+
+	return &promptui.PromptTemplates{
+		Valid:   bold(promptui.IconGood) + " " + label,
+		Invalid: bold(promptui.IconBad) + " " + label,
+		Success: `{{ .Text | faint }}{{ ":" | faint }} `,
+	}
+}
+
 func createSelectTemplates() *promptui.SelectTemplates {
 	return &promptui.SelectTemplates{
 		Label:    "{{ . }}?",
-		Active:   "→ {{if .IsSelected}}✔ {{end}} {{ .ID | cyan }}",
-		Inactive: "{{if .IsSelected }}✔ {{ .ID | green }} {{else}}{{ .ID | faint }}{{end}} ",
+		Active:   "→ {{if .IsSelected}}✔ {{end}} {{ .ID | cyan }} {{ .Description | faint }}",
+		Inactive: "{{if .IsSelected }}✔ {{ .ID | green }} {{else}}{{ .ID | faint }}{{end}} {{ .Description | faint }}",
+		Details:  "{{ .DetailsText }}",
 	}
 }
 
-// Prepend the prompt.Items with the continue item
+// Prepend the prompt.Items with the continue and select all items
 func prependItemsWithSpecialOptions(items []*Item) []*Item {
-	continueItem := &Item{ID: "Continue"}
-
-	items = append([]*Item{continueItem}, items...)
+	items = append([]*Item{continueItem, selectAllItem}, items...)
 
 	return items
 }
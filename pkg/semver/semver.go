@@ -0,0 +1,240 @@
+/*
+Package semver provides functionality for parsing and bumping semantic versions.
+
+This package parses semantic version tags, such as v1.2.3 or v2.0.0-rc.1, and calculates the next version
+from the level of the changes made since the previous release.
+
+Usage:
+  - Call the Parse function to parse a version tag.
+  - Call the Next function to calculate the next version for a bump level and a pre-release channel.
+
+Copyright © 2024 HENRI REMONEN <henri@remonen.fi>
+*/
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Bump represents the level of a version bump.
+type Bump int
+
+// Bump levels in increasing order.
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+var versionPattern = regexp.MustCompile(`^(v?)(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Version represents a semantic version with an optional prefix, such as "v".
+type Version struct {
+	Prefix     string
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+	// Tag is the string the version was parsed from, build metadata included, e.g. "v1.2.0+build.5".
+	// It is empty for calculated versions.
+	Tag string
+}
+
+// ParseBump parses a bump level name: major, minor, patch or none.
+func ParseBump(level string) (Bump, error) {
+	switch strings.ToLower(level) {
+	case "major":
+		return BumpMajor, nil
+	case "minor":
+		return BumpMinor, nil
+	case "patch":
+		return BumpPatch, nil
+	case "none", "":
+		return BumpNone, nil
+	default:
+		return BumpNone, fmt.Errorf("unknown bump level %q, use major, minor, patch or none", level)
+	}
+}
+
+func (b Bump) String() string {
+	return [...]string{"none", "patch", "minor", "major"}[b]
+}
+
+// Parse parses a semantic version, optionally prefixed with "v".
+func Parse(version string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return Version{}, fmt.Errorf("%q is not a semantic version", version)
+	}
+
+	// The pattern guarantees the numbers are valid.
+	major, _ := strconv.Atoi(match[2])
+	minor, _ := strconv.Atoi(match[3])
+	patch, _ := strconv.Atoi(match[4])
+
+	return Version{
+		Prefix:     match[1],
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		PreRelease: match[5],
+		Tag:        version,
+	}, nil
+}
+
+// String returns the version without its build metadata.
+func (v Version) String() string {
+	version := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		version += "-" + v.PreRelease
+	}
+	return version
+}
+
+// IsPreRelease reports whether the version is a pre-release, e.g. 1.0.0-rc.1.
+func (v Version) IsPreRelease() bool {
+	return v.PreRelease != ""
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is lower than, equal to or higher than other,
+// following the semantic versioning precedence rules.
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff != 0 {
+			return sign(diff)
+		}
+	}
+
+	switch {
+	case v.PreRelease == other.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case other.PreRelease == "":
+		return -1
+	}
+
+	return comparePreRelease(v.PreRelease, other.PreRelease)
+}
+
+// Increment returns the version bumped by the given level. Pre-release versions are promoted to
+// their release version when the bump does not go past it.
+func (v Version) Increment(bump Bump) Version {
+	next := Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+
+	switch bump {
+	case BumpMajor:
+		if !v.IsPreRelease() || v.Minor != 0 || v.Patch != 0 {
+			next.Major++
+			next.Minor, next.Patch = 0, 0
+		}
+	case BumpMinor:
+		if !v.IsPreRelease() || v.Patch != 0 {
+			next.Minor++
+			next.Patch = 0
+		}
+	case BumpPatch:
+		if !v.IsPreRelease() {
+			next.Patch++
+		}
+	case BumpNone:
+		return v
+	}
+
+	return next
+}
+
+// Next calculates the next version from the latest version and the bump level. When a pre-release
+// channel, such as "rc" or "beta", is given the next version is a pre-release on that channel, numbered
+// after the existing pre-releases of the same version.
+func Next(latest Version, bump Bump, channel string, existing []Version) Version {
+	next := latest.Increment(bump)
+	if channel == "" {
+		return next
+	}
+
+	number := 1
+	for _, version := range existing {
+		if version.Major != next.Major || version.Minor != next.Minor || version.Patch != next.Patch {
+			continue
+		}
+
+		if n, ok := preReleaseNumber(version.PreRelease, channel); ok && n >= number {
+			number = n + 1
+		}
+	}
+
+	next.PreRelease = fmt.Sprintf("%s.%d", channel, number)
+
+	return next
+}
+
+// Latest returns the highest version, optionally ignoring pre-releases, and whether one was found.
+func Latest(versions []Version, includePreReleases bool) (Version, bool) {
+	var latest Version
+	found := false
+
+	for _, version := range versions {
+		if version.IsPreRelease() && !includePreReleases {
+			continue
+		}
+		if !found || version.Compare(latest) > 0 {
+			latest = version
+			found = true
+		}
+	}
+
+	return latest, found
+}
+
+// preReleaseNumber returns N of a "<channel>.N" pre-release identifier.
+func preReleaseNumber(preRelease string, channel string) (int, bool) {
+	number, found := strings.CutPrefix(preRelease, channel+".")
+	if !found {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(number)
+	return n, err == nil
+}
+
+func comparePreRelease(a string, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNumber, aErr := strconv.Atoi(aParts[i])
+		bNumber, bErr := strconv.Atoi(bParts[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNumber != bNumber {
+				return sign(aNumber - bNumber)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return sign(len(aParts) - len(bParts))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,256 @@
+package semver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    Version
+		wantErr bool
+	}{
+		{
+			name:    "release",
+			version: "1.2.3",
+			want:    Version{Major: 1, Minor: 2, Patch: 3, Tag: "1.2.3"},
+		},
+		{
+			name:    "prefix",
+			version: "v1.2.3",
+			want:    Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3, Tag: "v1.2.3"},
+		},
+		{
+			name:    "pre-release",
+			version: "v2.0.0-rc.1",
+			want:    Version{Prefix: "v", Major: 2, PreRelease: "rc.1", Tag: "v2.0.0-rc.1"},
+		},
+		{
+			name:    "build metadata",
+			version: "v1.2.0-beta+build.5",
+			want:    Version{Prefix: "v", Major: 1, Minor: 2, PreRelease: "beta", Tag: "v1.2.0-beta+build.5"},
+		},
+		{name: "missing patch", version: "v1.2", wantErr: true},
+		{name: "leading zero", version: "v1.02.3", wantErr: true},
+		{name: "other prefix", version: "release-1.2.3", wantErr: true},
+		{name: "empty pre-release", version: "1.2.3-", wantErr: true},
+		{name: "empty", version: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "1.2.3", want: "1.2.3"},
+		{version: "v1.2.3-rc.1", want: "v1.2.3-rc.1"},
+		{version: "v1.2.3+build.5", want: "v1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := mustParse(t, tt.version).String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "1.2.3", b: "1.2.3", want: 0},
+		{a: "v1.2.3", b: "1.2.3", want: 0},
+		{a: "1.2.3+build.1", b: "1.2.3+build.2", want: 0},
+		{a: "2.0.0", b: "1.9.9", want: 1},
+		{a: "1.10.0", b: "1.9.0", want: 1},
+		{a: "1.2.10", b: "1.2.9", want: 1},
+		{a: "1.0.0-rc.1", b: "1.0.0", want: -1},
+		{a: "1.0.0", b: "1.0.0-rc.1", want: 1},
+		{a: "1.0.0-rc.1", b: "0.9.0", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := mustParse(t, tt.a).Compare(mustParse(t, tt.b)); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparePreReleaseOrdering(t *testing.T) {
+	// The example of the precedence rules in the semantic versioning specification.
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			want := sign(i - j)
+			if got := mustParse(t, ordered[i]).Compare(mustParse(t, ordered[j])); got != want {
+				t.Errorf("got %d comparing %s to %s, want %d", got, ordered[i], ordered[j], want)
+			}
+		}
+	}
+}
+
+func TestIncrement(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		bump    Bump
+		want    string
+	}{
+		{name: "major", version: "v1.2.3", bump: BumpMajor, want: "v2.0.0"},
+		{name: "minor", version: "v1.2.3", bump: BumpMinor, want: "v1.3.0"},
+		{name: "patch", version: "v1.2.3", bump: BumpPatch, want: "v1.2.4"},
+		{name: "none", version: "v1.2.3", bump: BumpNone, want: "v1.2.3"},
+		{name: "major while the major version is 0", version: "v0.4.1", bump: BumpMajor, want: "v1.0.0"},
+		{name: "minor while the major version is 0", version: "v0.4.1", bump: BumpMinor, want: "v0.5.0"},
+		{name: "pre-release of a major version", version: "v2.0.0-rc.1", bump: BumpMajor, want: "v2.0.0"},
+		{name: "minor bump of a major pre-release", version: "v2.0.0-rc.1", bump: BumpMinor, want: "v2.0.0"},
+		{name: "major bump of a minor pre-release", version: "v1.3.0-rc.1", bump: BumpMajor, want: "v2.0.0"},
+		{name: "pre-release of a minor version", version: "v1.3.0-rc.1", bump: BumpMinor, want: "v1.3.0"},
+		{name: "minor bump of a patch pre-release", version: "v1.2.4-rc.1", bump: BumpMinor, want: "v1.3.0"},
+		{name: "pre-release of a patch version", version: "v1.2.4-rc.1", bump: BumpPatch, want: "v1.2.4"},
+		{name: "no bump of a pre-release", version: "v1.2.4-rc.1", bump: BumpNone, want: "v1.2.4-rc.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustParse(t, tt.version).Increment(tt.bump).String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	existing := []Version{
+		{Prefix: "v", Major: 1, Minor: 2},
+		{Prefix: "v", Major: 1, Minor: 3, PreRelease: "rc.1"},
+		{Prefix: "v", Major: 1, Minor: 3, PreRelease: "rc.2"},
+		{Prefix: "v", Major: 1, Minor: 3, PreRelease: "beta.4"},
+		{Prefix: "v", Major: 2, PreRelease: "rc.7"},
+	}
+
+	tests := []struct {
+		name    string
+		latest  string
+		bump    Bump
+		channel string
+		want    string
+	}{
+		{name: "release", latest: "v1.2.0", bump: BumpMinor, want: "v1.3.0"},
+		{name: "next release candidate", latest: "v1.2.0", bump: BumpMinor, channel: "rc", want: "v1.3.0-rc.3"},
+		{name: "next beta", latest: "v1.2.0", bump: BumpMinor, channel: "beta", want: "v1.3.0-beta.5"},
+		{name: "first alpha", latest: "v1.2.0", bump: BumpMinor, channel: "alpha", want: "v1.3.0-alpha.1"},
+		{name: "first release candidate of a patch", latest: "v1.2.0", bump: BumpPatch, channel: "rc", want: "v1.2.1-rc.1"},
+		{name: "release candidate of a major version", latest: "v1.2.0", bump: BumpMajor, channel: "rc", want: "v2.0.0-rc.8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Next(mustParse(t, tt.latest), tt.bump, tt.channel, existing).String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	versions := []Version{
+		mustParse(t, "v1.2.0"),
+		mustParse(t, "v1.10.0"),
+		mustParse(t, "v2.0.0-rc.1"),
+		mustParse(t, "v1.9.0"),
+	}
+
+	tests := []struct {
+		name               string
+		versions           []Version
+		includePreReleases bool
+		want               string
+		wantFound          bool
+	}{
+		{name: "releases", versions: versions, want: "v1.10.0", wantFound: true},
+		{name: "pre-releases", versions: versions, includePreReleases: true, want: "v2.0.0-rc.1", wantFound: true},
+		{name: "only pre-releases", versions: versions[2:3], want: "0.0.0"},
+		{name: "no versions", want: "0.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := Latest(tt.versions, tt.includePreReleases)
+			if found != tt.wantFound || got.String() != tt.want {
+				t.Errorf("got %s (found %v), want %s (found %v)", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestParseBump(t *testing.T) {
+	tests := []struct {
+		level   string
+		want    Bump
+		wantErr bool
+	}{
+		{level: "major", want: BumpMajor},
+		{level: "Minor", want: BumpMinor},
+		{level: "patch", want: BumpPatch},
+		{level: "none", want: BumpNone},
+		{level: "", want: BumpNone},
+		{level: "huge", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			got, err := ParseBump(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && tt.level != "" && got.String() != strings.ToLower(tt.level) {
+				t.Errorf("got the name %q, want %q", got.String(), strings.ToLower(tt.level))
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, version string) Version {
+	t.Helper()
+
+	v, err := Parse(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}